@@ -3,12 +3,23 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all application configuration.
 type Config struct {
-	Telegram TelegramConfig
-	OpenAI   OpenAIConfig
+	Telegram  TelegramConfig
+	OpenAI    OpenAIConfig
+	Anthropic AnthropicConfig
+	Ollama    OllamaConfig
+	LlamaCpp  LlamaCppConfig
+	LLM       LLMConfig
+	Cache     CacheConfig
+	Queue     QueueConfig
+	Usage     UsageConfig
+	Salary    SalaryConfig
+	Dedup     DedupConfig
 }
 
 // TelegramConfig holds Telegram API credentials.
@@ -17,6 +28,9 @@ type TelegramConfig struct {
 	APIHash     string
 	Phone       string
 	SessionPath string
+	// AttachmentMaxSize is the largest document in bytes downloaded to read
+	// a vacancy from, 0 to ignore attachments.
+	AttachmentMaxSize int
 }
 
 // OpenAIConfig holds OpenAI API credentials.
@@ -25,21 +39,146 @@ type OpenAIConfig struct {
 	BaseURL string
 }
 
+// AnthropicConfig holds Anthropic API credentials.
+type AnthropicConfig struct {
+	APIKey  string
+	BaseURL string
+}
+
+// OllamaConfig holds the local Ollama server address.
+type OllamaConfig struct {
+	BaseURL string
+}
+
+// LlamaCppConfig holds the llama.cpp server address (OpenAI-compatible API).
+type LlamaCppConfig struct {
+	BaseURL string
+}
+
+// LLMConfig holds per-stage model chains as ordered "provider:model" specs.
+// The first entry is tried first, the rest are fallbacks.
+type LLMConfig struct {
+	ExtractionModels []string
+	OfferModels      []string
+
+	// RulesFallback uses the rule-based extractor when every extraction
+	// model fails instead of failing the job.
+	RulesFallback bool
+}
+
+// CacheConfig holds extraction cache settings.
+type CacheConfig struct {
+	Enabled bool
+	TTL     time.Duration
+}
+
+// UsageConfig holds LLM cost accounting and budget settings.
+type UsageConfig struct {
+	// Prices override the built-in price table as
+	// "provider:model=input/output" specs in USD per million tokens.
+	Prices []string
+
+	// Daily spend caps in USD per UTC day, 0 means unlimited.
+	// Background extraction pauses at ExtractionDailyBudget while
+	// offer generation keeps working up to OfferDailyBudget.
+	ExtractionDailyBudget float64
+	OfferDailyBudget      float64
+}
+
+// SalaryConfig holds salary normalization settings.
+type SalaryConfig struct {
+	// BaseCurrency is the ISO 4217 code salaries are converted to.
+	BaseCurrency string
+
+	// TaxBasis is "gross" or "net": monthly amounts of salaries quoted the
+	// other way are converted with the flat TaxRate.
+	TaxBasis string
+	TaxRate  float64
+}
+
+// DedupConfig holds repost detection settings.
+type DedupConfig struct {
+	// NearEnabled links reposts with slightly edited text to the job
+	// collected first once their extraction shows the same vacancy.
+	NearEnabled bool
+
+	// MaxDistance is the number of differing SimHash bits out of 64 up to
+	// which texts are considered the same vacancy. Template posts of
+	// different vacancies can be 6 bits apart, so keep it low. Up to 7 bits
+	// are always found; larger distances only if the fingerprints still
+	// share an 8-bit band.
+	MaxDistance int
+}
+
+// QueueConfig holds job processing queue settings.
+type QueueConfig struct {
+	Concurrency   int
+	MaxAttempts   int
+	BaseBackoff   time.Duration
+	MaxBackoff    time.Duration
+	LeaseTimeout  time.Duration
+	PollInterval  time.Duration
+	ShutdownGrace time.Duration
+}
+
 // Load reads configuration from environment variables.
 func Load() Config {
 	apiID, _ := strconv.Atoi(os.Getenv("TG_API_ID"))
 
 	return Config{
 		Telegram: TelegramConfig{
-			APIID:       apiID,
-			APIHash:     os.Getenv("TG_API_HASH"),
-			Phone:       os.Getenv("TG_PHONE"),
-			SessionPath: getEnvOrDefault("TG_SESSION_PATH", "session.json"),
+			APIID:             apiID,
+			APIHash:           os.Getenv("TG_API_HASH"),
+			Phone:             os.Getenv("TG_PHONE"),
+			SessionPath:       getEnvOrDefault("TG_SESSION_PATH", "session.json"),
+			AttachmentMaxSize: getEnvSize("TG_ATTACHMENT_MAX_SIZE", 10<<20),
 		},
 		OpenAI: OpenAIConfig{
 			APIKey:  os.Getenv("OPENAI_API_KEY"),
 			BaseURL: os.Getenv("OPENAI_BASE_URL"),
 		},
+		Anthropic: AnthropicConfig{
+			APIKey:  os.Getenv("ANTHROPIC_API_KEY"),
+			BaseURL: os.Getenv("ANTHROPIC_BASE_URL"),
+		},
+		Ollama: OllamaConfig{
+			BaseURL: os.Getenv("OLLAMA_BASE_URL"),
+		},
+		LlamaCpp: LlamaCppConfig{
+			BaseURL: os.Getenv("LLAMACPP_BASE_URL"),
+		},
+		LLM: LLMConfig{
+			ExtractionModels: getEnvList("LLM_EXTRACTION_MODELS", "openai:gpt-5-nano"),
+			OfferModels:      getEnvList("LLM_OFFER_MODELS", "openai:gpt-5.2"),
+			RulesFallback:    getEnvBool("LLM_RULES_FALLBACK", true),
+		},
+		Cache: CacheConfig{
+			Enabled: getEnvBool("EXTRACTION_CACHE_ENABLED", true),
+			TTL:     getEnvDuration("EXTRACTION_CACHE_TTL", 30*24*time.Hour),
+		},
+		Queue: QueueConfig{
+			Concurrency:   getEnvInt("JOB_QUEUE_CONCURRENCY", 2),
+			MaxAttempts:   getEnvInt("JOB_QUEUE_MAX_ATTEMPTS", 5),
+			BaseBackoff:   getEnvDuration("JOB_QUEUE_BASE_BACKOFF", 30*time.Second),
+			MaxBackoff:    getEnvDuration("JOB_QUEUE_MAX_BACKOFF", 30*time.Minute),
+			LeaseTimeout:  getEnvDuration("JOB_QUEUE_LEASE_TIMEOUT", 2*time.Minute),
+			PollInterval:  getEnvDuration("JOB_QUEUE_POLL_INTERVAL", 2*time.Second),
+			ShutdownGrace: getEnvDuration("JOB_QUEUE_SHUTDOWN_GRACE", 20*time.Second),
+		},
+		Usage: UsageConfig{
+			Prices:                getEnvList("LLM_PRICES", ""),
+			ExtractionDailyBudget: getEnvFloat("LLM_DAILY_BUDGET_USD", 0),
+			OfferDailyBudget:      getEnvFloat("LLM_OFFER_DAILY_BUDGET_USD", 0),
+		},
+		Salary: SalaryConfig{
+			BaseCurrency: strings.ToUpper(getEnvOrDefault("SALARY_BASE_CURRENCY", "USD")),
+			TaxBasis:     strings.ToLower(getEnvOrDefault("SALARY_TAX_BASIS", "net")),
+			TaxRate:      getEnvFloat("SALARY_TAX_RATE", 0.13),
+		},
+		Dedup: DedupConfig{
+			NearEnabled: getEnvBool("DEDUP_NEAR_ENABLED", true),
+			MaxDistance: getEnvInt("DEDUP_MAX_DISTANCE", 3),
+		},
 	}
 }
 
@@ -49,3 +188,49 @@ func getEnvOrDefault(key, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+func getEnvInt(key string, defaultVal int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return defaultVal
+}
+
+// getEnvSize is getEnvInt accepting 0, for sizes where 0 turns a feature off.
+func getEnvSize(key string, defaultVal int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v >= 0 {
+		return v
+	}
+	return defaultVal
+}
+
+func getEnvFloat(key string, defaultVal float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && v >= 0 {
+		return v
+	}
+	return defaultVal
+}
+
+func getEnvBool(key string, defaultVal bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return v
+	}
+	return defaultVal
+}
+
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return defaultVal
+}
+
+func getEnvList(key, defaultVal string) []string {
+	var list []string
+	for _, v := range strings.Split(getEnvOrDefault(key, defaultVal), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
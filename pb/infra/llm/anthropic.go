@@ -0,0 +1,171 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	anthropicDefaultBaseURL   = "https://api.anthropic.com/v1"
+	anthropicVersion          = "2023-06-01"
+	anthropicDefaultMaxTokens = 4096
+)
+
+// Anthropic talks to the Anthropic Messages API.
+// Structured output is requested by forcing a single tool call whose
+// input schema is the requested JSON schema.
+type Anthropic struct {
+	apiKey  string
+	baseURL string
+	http    *http.Client
+}
+
+// NewAnthropic creates a provider for the Anthropic Messages API.
+func NewAnthropic(apiKey, baseURL string) *Anthropic {
+	if baseURL == "" {
+		baseURL = anthropicDefaultBaseURL
+	}
+	return &Anthropic{
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    http.DefaultClient,
+	}
+}
+
+// Name implements Provider.
+func (p *Anthropic) Name() string {
+	return "anthropic"
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type anthropicRequest struct {
+	Model      string               `json:"model"`
+	System     string               `json:"system,omitempty"`
+	Messages   []anthropicMessage   `json:"messages"`
+	MaxTokens  int                  `json:"max_tokens"`
+	Tools      []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type anthropicResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Input json.RawMessage `json:"input"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Complete implements Completer.
+func (p *Anthropic) Complete(ctx context.Context, req Request) (Response, error) {
+	body := anthropicRequest{
+		Model:     req.Model,
+		System:    req.System,
+		MaxTokens: req.MaxTokens,
+	}
+	if body.MaxTokens == 0 {
+		body.MaxTokens = anthropicDefaultMaxTokens
+	}
+	for _, m := range req.Messages {
+		body.Messages = append(body.Messages, anthropicMessage{Role: string(m.Role), Content: m.Content})
+	}
+
+	if req.Schema != nil {
+		body.Tools = []anthropicTool{{
+			Name:        req.SchemaName,
+			Description: "Return the result in this structure.",
+			InputSchema: req.Schema,
+		}}
+		body.ToolChoice = &anthropicToolChoice{Type: "tool", Name: req.SchemaName}
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return Response{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/messages", bytes.NewReader(payload))
+	if err != nil {
+		return Response{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", p.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+
+	httpResp, err := p.http.Do(httpReq)
+	if err != nil {
+		return Response{}, err
+	}
+	defer httpResp.Body.Close()
+
+	raw, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var resp anthropicResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return Response{}, fmt.Errorf("anthropic: status %d: %w", httpResp.StatusCode, err)
+	}
+	if resp.Error != nil {
+		return Response{}, fmt.Errorf("anthropic: %s: %s", resp.Error.Type, resp.Error.Message)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return Response{}, fmt.Errorf("anthropic: unexpected status %d", httpResp.StatusCode)
+	}
+
+	var content strings.Builder
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "tool_use":
+			if req.Schema != nil {
+				content.Reset()
+				content.Write(block.Input)
+			}
+		case "text":
+			if req.Schema == nil {
+				content.WriteString(block.Text)
+			}
+		}
+	}
+	if content.Len() == 0 {
+		return Response{}, errors.New("anthropic: empty completion")
+	}
+
+	return Response{
+		Content:          content.String(),
+		Provider:         p.Name(),
+		Model:            resp.Model,
+		PromptTokens:     resp.Usage.InputTokens,
+		CompletionTokens: resp.Usage.OutputTokens,
+	}, nil
+}
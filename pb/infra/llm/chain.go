@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Target is a provider paired with the model to request from it.
+type Target struct {
+	Provider Provider
+	Model    string
+}
+
+// Chain tries its targets in order and returns the first successful response.
+// It is used to run a local model first and fall back to a hosted one.
+type Chain struct {
+	targets []Target
+	logger  *zap.Logger
+}
+
+// NewChain builds a chain from "provider:model" specs, e.g.
+// "ollama:qwen2.5:7b" or "openai:gpt-5-nano". Providers are looked up by name.
+func NewChain(specs []string, providers []Provider, logger *zap.Logger) (*Chain, error) {
+	byName := make(map[string]Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+
+	chain := &Chain{logger: logger}
+	for _, spec := range specs {
+		name, model, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		if !ok || model == "" {
+			return nil, fmt.Errorf("invalid model spec %q, expected provider:model", spec)
+		}
+
+		provider, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("provider %q is not configured", name)
+		}
+
+		chain.targets = append(chain.targets, Target{Provider: provider, Model: model})
+	}
+
+	if len(chain.targets) == 0 {
+		return nil, errors.New("empty model chain")
+	}
+
+	return chain, nil
+}
+
+// Complete implements Completer.
+func (c *Chain) Complete(ctx context.Context, req Request) (Response, error) {
+	var errs []error
+
+	for i, target := range c.targets {
+		req.Model = target.Model
+
+		resp, err := target.Provider.Complete(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s/%s: %w", target.Provider.Name(), target.Model, err))
+
+		// Do not burn through fallbacks once the caller gave up
+		if ctx.Err() != nil {
+			break
+		}
+
+		if i < len(c.targets)-1 {
+			c.logger.Warn("LLM provider failed, falling back",
+				zap.String("provider", target.Provider.Name()),
+				zap.String("model", target.Model),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return Response{}, errors.Join(errs...)
+}
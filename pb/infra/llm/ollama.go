@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const ollamaDefaultBaseURL = "http://localhost:11434"
+
+// Ollama talks to a local Ollama server via its native chat API.
+// Structured output uses Ollama's schema-constrained "format" option.
+type Ollama struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewOllama creates a provider for a local Ollama server.
+func NewOllama(baseURL string) *Ollama {
+	if baseURL == "" {
+		baseURL = ollamaDefaultBaseURL
+	}
+	return &Ollama{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    http.DefaultClient,
+	}
+}
+
+// Name implements Provider.
+func (p *Ollama) Name() string {
+	return "ollama"
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   json.RawMessage `json:"format,omitempty"`
+	Options  map[string]any  `json:"options,omitempty"`
+}
+
+type ollamaResponse struct {
+	Model   string        `json:"model"`
+	Message ollamaMessage `json:"message"`
+	// Token counts as reported by Ollama
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error"`
+}
+
+// Complete implements Completer.
+func (p *Ollama) Complete(ctx context.Context, req Request) (Response, error) {
+	body := ollamaRequest{
+		Model:  req.Model,
+		Stream: false,
+		Format: req.Schema,
+	}
+	if req.System != "" {
+		body.Messages = append(body.Messages, ollamaMessage{Role: "system", Content: req.System})
+	}
+	for _, m := range req.Messages {
+		body.Messages = append(body.Messages, ollamaMessage{Role: string(m.Role), Content: m.Content})
+	}
+	if req.MaxTokens > 0 {
+		body.Options = map[string]any{"num_predict": req.MaxTokens}
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return Response{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/chat", bytes.NewReader(payload))
+	if err != nil {
+		return Response{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := p.http.Do(httpReq)
+	if err != nil {
+		return Response{}, err
+	}
+	defer httpResp.Body.Close()
+
+	raw, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var resp ollamaResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return Response{}, fmt.Errorf("ollama: status %d: %w", httpResp.StatusCode, err)
+	}
+	if resp.Error != "" {
+		return Response{}, fmt.Errorf("ollama: %s", resp.Error)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return Response{}, fmt.Errorf("ollama: unexpected status %d", httpResp.StatusCode)
+	}
+
+	return Response{
+		Content:          resp.Message.Content,
+		Provider:         p.Name(),
+		Model:            resp.Model,
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+	}, nil
+}
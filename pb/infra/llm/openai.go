@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"context"
+	"errors"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// OpenAI talks to the OpenAI Chat Completions API or any compatible server
+// (llama.cpp, vLLM, LM Studio).
+type OpenAI struct {
+	name   string
+	client *openai.Client
+}
+
+// NewOpenAI creates a provider for the official OpenAI API.
+func NewOpenAI(apiKey, baseURL string) *OpenAI {
+	return NewOpenAICompatible("openai", apiKey, baseURL)
+}
+
+// NewOpenAICompatible creates a provider for an OpenAI-compatible server
+// registered under the given name.
+func NewOpenAICompatible(name, apiKey, baseURL string) *OpenAI {
+	config := openai.DefaultConfig(apiKey)
+	if baseURL != "" {
+		config.BaseURL = baseURL
+	}
+	return &OpenAI{
+		name:   name,
+		client: openai.NewClientWithConfig(config),
+	}
+}
+
+// Name implements Provider.
+func (p *OpenAI) Name() string {
+	return p.name
+}
+
+// Complete implements Completer.
+func (p *OpenAI) Complete(ctx context.Context, req Request) (Response, error) {
+	messages := make([]openai.ChatCompletionMessage, 0, len(req.Messages)+1)
+	if req.System != "" {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: req.System,
+		})
+	}
+	for _, m := range req.Messages {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    string(m.Role),
+			Content: m.Content,
+		})
+	}
+
+	chatReq := openai.ChatCompletionRequest{
+		Model:               req.Model,
+		Messages:            messages,
+		MaxCompletionTokens: req.MaxTokens,
+	}
+
+	if req.Schema != nil {
+		chatReq.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   req.SchemaName,
+				Schema: req.Schema,
+				Strict: true,
+			},
+		}
+	}
+
+	resp, err := p.client.CreateChatCompletion(ctx, chatReq)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if len(resp.Choices) == 0 {
+		return Response{}, errors.New("empty completion")
+	}
+
+	// Prefer the exact model snapshot reported by the API
+	model := resp.Model
+	if model == "" {
+		model = req.Model
+	}
+
+	return Response{
+		Content:          resp.Choices[0].Message.Content,
+		Provider:         p.name,
+		Model:            model,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}, nil
+}
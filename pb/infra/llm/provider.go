@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Role is the author of a chat message.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+)
+
+// Message is a single chat turn.
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// Request is a provider-agnostic chat completion request.
+type Request struct {
+	// Model is filled in by the Chain for each target.
+	Model    string
+	System   string
+	Messages []Message
+
+	// SchemaName and Schema request structured JSON output when set.
+	SchemaName string
+	Schema     json.RawMessage
+
+	// MaxTokens limits the completion; providers that require it use a default.
+	MaxTokens int
+}
+
+// Response is a provider-agnostic chat completion result.
+type Response struct {
+	Content          string
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Completer produces chat completions.
+// Implemented by single providers and by Chain.
+type Completer interface {
+	Complete(ctx context.Context, req Request) (Response, error)
+}
+
+// Provider is a concrete LLM backend.
+type Provider interface {
+	Completer
+
+	// Name identifies the provider in chain specs, e.g. "openai".
+	Name() string
+}
+
+// UserPrompt builds a single-turn request.
+func UserPrompt(system, user string) Request {
+	return Request{
+		System:   system,
+		Messages: []Message{{Role: RoleUser, Content: user}},
+	}
+}
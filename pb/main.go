@@ -17,10 +17,15 @@ import (
 	"svpb-tmpl/config"
 	"svpb-tmpl/infra/llm"
 	collector_in "svpb-tmpl/pkg/collector/adapters/in"
+	collector_out "svpb-tmpl/pkg/collector/adapters/out"
 	collector_usecases "svpb-tmpl/pkg/collector/usecases"
 	job_in "svpb-tmpl/pkg/job/adapters/in"
 	job_out "svpb-tmpl/pkg/job/adapters/out"
+	job_core "svpb-tmpl/pkg/job/core"
 	job_usecases "svpb-tmpl/pkg/job/usecases"
+	usage_in "svpb-tmpl/pkg/usage/adapters/in"
+	usage_core "svpb-tmpl/pkg/usage/core"
+	usage_usecases "svpb-tmpl/pkg/usage/usecases"
 
 	_ "svpb-tmpl/migrations"
 )
@@ -34,32 +39,107 @@ func main() {
 	defer logger.Sync()
 
 	// --- Global Infrastructure ---
-	llmClient := llm.NewClient(cfg.OpenAI.APIKey, cfg.OpenAI.BaseURL)
+	llmProviders := newLLMProviders(cfg)
+
+	extractionLLM, err := llm.NewChain(cfg.LLM.ExtractionModels, llmProviders, logger)
+	if err != nil {
+		log.Fatalf("Invalid LLM_EXTRACTION_MODELS: %v", err)
+	}
+
+	offerLLM, err := llm.NewChain(cfg.LLM.OfferModels, llmProviders, logger)
+	if err != nil {
+		log.Fatalf("Invalid LLM_OFFER_MODELS: %v", err)
+	}
+
+	// --- Usage Module ---
+	// Usecase
+	prices, err := usage_core.ParsePrices(cfg.Usage.Prices)
+	if err != nil {
+		log.Fatalf("Invalid LLM_PRICES: %v", err)
+	}
+	usageService := usage_usecases.NewService(app, prices, cfg.Usage, logger)
+
+	// Adapters/in: every LLM call is metered per stage
+	extractionMeter := usage_in.NewMeter(extractionLLM, usage_core.StageExtraction, usageService, logger)
+	offerMeter := usage_in.NewMeter(offerLLM, usage_core.StageOffer, usageService, logger)
+	usageAPI := usage_in.NewAPI(usageService, logger)
+
+	// Register usage module
+	usageAPI.Register(app)
 
 	// --- Job Module ---
 	// Adapters/out (driven ports implementations)
-	jobExtractor := job_out.NewExtractor(llmClient)
-	offerGenerator := job_out.NewOfferGenerator(llmClient)
+	llmExtractor := job_out.NewExtractor(extractionMeter)
+	extractionCache := job_out.NewCachedExtractor(
+		app,
+		llmExtractor,
+		cfg.LLM.ExtractionModels,
+		llmExtractor.PromptVersion(),
+		cfg.Cache.TTL,
+		logger,
+	)
+	offerGenerator := job_out.NewOfferGenerator(offerMeter)
+
+	var cachedExtractor job_core.JobExtractor = llmExtractor
+	if cfg.Cache.Enabled {
+		cachedExtractor = extractionCache
+	}
+	ruleExtractor := job_out.NewRuleExtractor()
+	jobExtractor := job_out.NewFallbackExtractor(
+		cachedExtractor,
+		ruleExtractor,
+		cfg.LLM.RulesFallback,
+		logger,
+	)
 
 	// Usecase
-	jobService := job_usecases.NewService(app, jobExtractor, offerGenerator, logger)
+	jobSkills := job_usecases.NewSkills(app, logger)
+	jobCompanies := job_usecases.NewCompanies(app, cfg.Salary, logger)
+	jobRates := job_usecases.NewRates(app, logger)
+	jobService := job_usecases.NewService(
+		app,
+		jobExtractor,
+		ruleExtractor,
+		offerGenerator,
+		jobSkills,
+		jobCompanies,
+		jobRates,
+		cfg.Salary,
+		cfg.Dedup,
+		usageService,
+		logger,
+	)
+	jobQueue := job_usecases.NewQueue(app, cfg.Queue, logger)
 
 	// Adapters/in (driving ports)
-	jobAPI := job_in.NewAPI(jobService)
-	jobHooks := job_in.NewHooks(jobService, logger)
+	jobAPI := job_in.NewAPI(jobService, extractionCache, jobSkills, jobCompanies, logger)
+	jobHooks := job_in.NewHooks(jobQueue, extractionCache, jobSkills, logger)
+	jobWorker := job_in.NewWorker(cfg.Queue, jobQueue, jobService, usageService, logger)
+	jobCLI := job_in.NewCLI(jobService, jobSkills, jobCompanies, jobRates, cfg.Salary, logger)
 
 	// Register job module
 	jobAPI.Register(app)
 	jobHooks.Register(app)
+	jobWorker.Register(app)
+	jobCLI.Register(app)
 
 	// --- Collector Module ---
+	// Adapters/out
+	tgState := collector_out.NewTGState(app)
+	documents := collector_out.NewDocuments()
+
 	// Usecase (depends on job service interface)
-	collectorService := collector_usecases.NewService(jobService, logger)
+	collectorService := collector_usecases.NewService(jobService, documents, logger)
+	backfillCursors := collector_usecases.NewCursors(app)
+	collectorSources := collector_usecases.NewSources(app, jobService, logger)
+	pendingAlbums := collector_usecases.NewPendingAlbums(app)
 
 	// Adapters/in
-	tgAdapter := collector_in.NewTG(cfg.Telegram, collectorService, logger)
+	tgAdapter := collector_in.NewTG(cfg.Telegram, collectorService, backfillCursors, collectorSources, tgState, pendingAlbums, logger)
+	collectorAPI := collector_in.NewAPI(collectorService, tgAdapter, backfillCursors, logger)
 
 	// Register collector module
+	collectorAPI.Register(app)
 	tgAdapter.RegisterCommand(app)
 
 	// --- Static File Serving ---
@@ -123,6 +203,27 @@ func main() {
 	}
 }
 
+// newLLMProviders creates the LLM providers available to model chains.
+// Optional providers are only registered when configured.
+func newLLMProviders(cfg config.Config) []llm.Provider {
+	providers := []llm.Provider{
+		llm.NewOpenAI(cfg.OpenAI.APIKey, cfg.OpenAI.BaseURL),
+	}
+
+	if cfg.Anthropic.APIKey != "" {
+		providers = append(providers, llm.NewAnthropic(cfg.Anthropic.APIKey, cfg.Anthropic.BaseURL))
+	}
+
+	if cfg.LlamaCpp.BaseURL != "" {
+		providers = append(providers, llm.NewOpenAICompatible("llamacpp", "", cfg.LlamaCpp.BaseURL))
+	}
+
+	// Local Ollama needs no credentials
+	providers = append(providers, llm.NewOllama(cfg.Ollama.BaseURL))
+
+	return providers
+}
+
 // startCollector runs the Telegram message listener in the background.
 func startTGCollector(adapter *collector_in.TGAdapter, logger *zap.Logger) {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
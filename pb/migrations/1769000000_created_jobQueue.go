@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection("jobQueue")
+
+		// Queued job, entry goes away with the job
+		collection.Fields.Add(&core.RelationField{
+			Name:          "job",
+			CollectionId:  jobs.Id,
+			MaxSelect:     1,
+			Required:      true,
+			CascadeDelete: true,
+		})
+
+		collection.Fields.Add(&core.SelectField{
+			Name:      "status",
+			MaxSelect: 1,
+			Values:    []string{"pending", "leased", "dead"},
+		})
+
+		// Number of times the entry was leased
+		collection.Fields.Add(&core.NumberField{
+			Name:    "attempts",
+			OnlyInt: true,
+		})
+
+		// Earliest time the entry may be leased (used for backoff)
+		collection.Fields.Add(&core.DateField{
+			Name: "runAt",
+		})
+
+		// Lease deadline after which another worker may take over
+		collection.Fields.Add(&core.DateField{
+			Name: "leasedUntil",
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name: "lastError",
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		collection.AddIndex("idx_jobQueue_job", true, "job", "")
+		collection.AddIndex("idx_jobQueue_status_runAt", false, "status, runAt", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("jobQueue")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"slices"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Transient processing errors get their own status
+		if status, ok := collection.Fields.GetByName("status").(*core.SelectField); ok {
+			if !slices.Contains(status.Values, "failed") {
+				status.Values = append(status.Values, "failed")
+			}
+		}
+
+		// Human readable reason for rejected/failed jobs
+		collection.Fields.Add(&core.TextField{
+			Name:     "statusReason",
+			Required: false,
+		})
+
+		// Last technical error from processing
+		collection.Fields.Add(&core.TextField{
+			Name:     "lastError",
+			Required: false,
+		})
+
+		// Number of processing attempts
+		collection.Fields.Add(&core.NumberField{
+			Name:    "attempts",
+			OnlyInt: true,
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		if status, ok := collection.Fields.GetByName("status").(*core.SelectField); ok {
+			status.Values = slices.DeleteFunc(status.Values, func(v string) bool {
+				return v == "failed"
+			})
+		}
+
+		collection.Fields.RemoveByName("statusReason")
+		collection.Fields.RemoveByName("lastError")
+		collection.Fields.RemoveByName("attempts")
+
+		return app.Save(collection)
+	})
+}
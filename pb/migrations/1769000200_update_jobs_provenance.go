@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Extraction provenance
+		collection.Fields.Add(&core.TextField{
+			Name:     "extractor",
+			Required: false,
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name:     "extractionModel",
+			Required: false,
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name:     "promptVersion",
+			Required: false,
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name:     "promptHash",
+			Required: false,
+		})
+
+		// Token usage of the extraction call
+		collection.Fields.Add(&core.NumberField{
+			Name:    "promptTokens",
+			OnlyInt: true,
+		})
+
+		collection.Fields.Add(&core.NumberField{
+			Name:    "completionTokens",
+			OnlyInt: true,
+		})
+
+		collection.Fields.Add(&core.DateField{
+			Name: "extractedAt",
+		})
+
+		// Find jobs to reprocess after prompt upgrades
+		collection.AddIndex("idx_jobs_promptVersion", false, "promptVersion", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		collection.RemoveIndex("idx_jobs_promptVersion")
+
+		collection.Fields.RemoveByName("extractor")
+		collection.Fields.RemoveByName("extractionModel")
+		collection.Fields.RemoveByName("promptVersion")
+		collection.Fields.RemoveByName("promptHash")
+		collection.Fields.RemoveByName("promptTokens")
+		collection.Fields.RemoveByName("completionTokens")
+		collection.Fields.RemoveByName("extractedAt")
+
+		return app.Save(collection)
+	})
+}
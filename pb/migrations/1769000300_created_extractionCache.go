@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("extractionCache")
+
+		// sha256 of (textHash, model, prompt)
+		collection.Fields.Add(&core.TextField{
+			Name:     "cacheKey",
+			Required: true,
+		})
+
+		// Normalized text hash, same as jobs.hash
+		collection.Fields.Add(&core.TextField{
+			Name: "textHash",
+		})
+
+		// Model chain and prompt version the entry was produced with
+		collection.Fields.Add(&core.TextField{
+			Name: "model",
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name: "prompt",
+		})
+
+		// Cached ParsedData and its provenance
+		collection.Fields.Add(&core.JSONField{
+			Name: "data",
+		})
+
+		collection.Fields.Add(&core.JSONField{
+			Name: "provenance",
+		})
+
+		// Tokens spent on the original call, saved on every hit
+		collection.Fields.Add(&core.NumberField{
+			Name:    "tokens",
+			OnlyInt: true,
+		})
+
+		collection.Fields.Add(&core.NumberField{
+			Name:    "hits",
+			OnlyInt: true,
+		})
+
+		collection.Fields.Add(&core.DateField{
+			Name: "expiresAt",
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		collection.AddIndex("idx_extractionCache_cacheKey", true, "cacheKey", "")
+		collection.AddIndex("idx_extractionCache_expiresAt", false, "expiresAt", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("extractionCache")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(collection)
+	})
+}
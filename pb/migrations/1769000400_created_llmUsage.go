@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		users, err := app.FindCollectionByNameOrId("_pb_users_auth_")
+		if err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection("llm_usage")
+
+		// Pipeline stage that made the call
+		collection.Fields.Add(&core.SelectField{
+			Name:      "stage",
+			MaxSelect: 1,
+			Required:  true,
+			Values:    []string{"extraction", "offer"},
+		})
+
+		// Provider and model that actually answered (after fallbacks)
+		collection.Fields.Add(&core.TextField{
+			Name: "provider",
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name: "model",
+		})
+
+		collection.Fields.Add(&core.NumberField{
+			Name:    "promptTokens",
+			OnlyInt: true,
+		})
+
+		collection.Fields.Add(&core.NumberField{
+			Name:    "completionTokens",
+			OnlyInt: true,
+		})
+
+		// Cost in USD computed from the price table at call time
+		collection.Fields.Add(&core.NumberField{
+			Name: "cost",
+		})
+
+		// User who requested the call (offers only)
+		collection.Fields.Add(&core.RelationField{
+			Name:         "user",
+			CollectionId: users.Id,
+			MaxSelect:    1,
+		})
+
+		// Job the call was made for; usage is kept when the job is deleted
+		collection.Fields.Add(&core.RelationField{
+			Name:         "job",
+			CollectionId: jobs.Id,
+			MaxSelect:    1,
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		collection.AddIndex("idx_llm_usage_stage_created", false, "stage, created", "")
+		collection.AddIndex("idx_llm_usage_user", false, "user", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("llm_usage")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(collection)
+	})
+}
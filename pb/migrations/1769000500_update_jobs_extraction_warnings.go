@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Sanity check findings from comparing LLM output with the rule-based pre-fill
+		collection.Fields.Add(&core.JSONField{
+			Name: "extractionWarnings",
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		collection.Fields.RemoveByName("extractionWarnings")
+
+		return app.Save(collection)
+	})
+}
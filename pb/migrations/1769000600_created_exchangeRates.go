@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("exchangeRates")
+
+		// ISO 4217 code
+		collection.Fields.Add(&core.TextField{
+			Name:     "currency",
+			Required: true,
+			Max:      3,
+			Min:      3,
+		})
+
+		// Price of one US dollar in this currency
+		collection.Fields.Add(&core.NumberField{
+			Name:     "perUsd",
+			Required: true,
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		collection.AddIndex("idx_exchangeRates_currency", true, "currency", "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// Starting point only, keep up to date with `jobs rates set`
+		seed := map[string]float64{
+			"USD": 1,
+			"EUR": 0.86,
+			"GBP": 0.75,
+			"RUB": 80,
+			"KZT": 510,
+			"UAH": 42,
+			"BYN": 3,
+		}
+
+		for currency, perUSD := range seed {
+			record := core.NewRecord(collection)
+			record.Set("currency", currency)
+			record.Set("perUsd", perUSD)
+			if err := app.Save(record); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("exchangeRates")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(collection)
+	})
+}
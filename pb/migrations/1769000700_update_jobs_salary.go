@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Salary terms as stated in the post
+		collection.Fields.Add(&core.SelectField{
+			Name:      "salaryPeriod",
+			MaxSelect: 1,
+			Values:    []string{"hour", "month", "year"},
+		})
+
+		collection.Fields.Add(&core.SelectField{
+			Name:      "salaryTax",
+			MaxSelect: 1,
+			Values:    []string{"gross", "net"},
+		})
+
+		// Monthly salary in salaryBaseCurrency, comparable across jobs
+		collection.Fields.Add(&core.NumberField{
+			Name:    "salaryMinMonthly",
+			OnlyInt: true,
+		})
+
+		collection.Fields.Add(&core.NumberField{
+			Name:    "salaryMaxMonthly",
+			OnlyInt: true,
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name: "salaryBaseCurrency",
+		})
+
+		// Filter and sort by comparable pay
+		collection.AddIndex("idx_jobs_salaryMinMonthly", false, "salaryMinMonthly", "")
+		collection.AddIndex("idx_jobs_salaryMaxMonthly", false, "salaryMaxMonthly", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		collection.RemoveIndex("idx_jobs_salaryMinMonthly")
+		collection.RemoveIndex("idx_jobs_salaryMaxMonthly")
+
+		collection.Fields.RemoveByName("salaryPeriod")
+		collection.Fields.RemoveByName("salaryTax")
+		collection.Fields.RemoveByName("salaryMinMonthly")
+		collection.Fields.RemoveByName("salaryMaxMonthly")
+		collection.Fields.RemoveByName("salaryBaseCurrency")
+
+		return app.Save(collection)
+	})
+}
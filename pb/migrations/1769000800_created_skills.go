@@ -0,0 +1,151 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("skills")
+		collection.ListRule = types.Pointer("")
+		collection.ViewRule = types.Pointer("")
+
+		// Canonical display name, e.g. "Go"
+		collection.Fields.Add(&core.TextField{
+			Name:     "name",
+			Required: true,
+		})
+
+		// Other spellings resolving to this skill, e.g. ["golang"]
+		collection.Fields.Add(&core.JSONField{
+			Name: "aliases",
+		})
+
+		collection.Fields.Add(&core.SelectField{
+			Name:      "category",
+			MaxSelect: 1,
+			Values:    []string{"language", "framework", "cloud", "db", "tool", "other"},
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		collection.AddIndex("idx_skills_name", true, "name COLLATE NOCASE", "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// Starting taxonomy, curate with `jobs skills`
+		seed := []struct {
+			name     string
+			category string
+			aliases  []string
+		}{
+			{"Go", "language", []string{"golang", "go lang"}},
+			{"Python", "language", []string{"python3", "py"}},
+			{"JavaScript", "language", []string{"js", "es6", "ecmascript"}},
+			{"TypeScript", "language", []string{"ts"}},
+			{"Java", "language", nil},
+			{"Kotlin", "language", nil},
+			{"Swift", "language", nil},
+			{"C#", "language", []string{"csharp", "c sharp"}},
+			{"C++", "language", []string{"cpp"}},
+			{"PHP", "language", nil},
+			{"Ruby", "language", nil},
+			{"Rust", "language", nil},
+			{"Scala", "language", nil},
+			{"SQL", "language", nil},
+			{"React", "framework", []string{"react.js", "reactjs"}},
+			{"Vue", "framework", []string{"vue.js", "vuejs"}},
+			{"Svelte", "framework", nil},
+			{"SvelteKit", "framework", []string{"svelte kit"}},
+			{"Angular", "framework", []string{"angularjs"}},
+			{"Node.js", "framework", []string{"node", "nodejs"}},
+			{"Next.js", "framework", []string{"next", "nextjs"}},
+			{".NET", "framework", []string{"dotnet", "asp.net"}},
+			{"Django", "framework", nil},
+			{"FastAPI", "framework", nil},
+			{"Flask", "framework", nil},
+			{"Spring", "framework", []string{"spring boot"}},
+			{"Laravel", "framework", nil},
+			{"Flutter", "framework", nil},
+			{"PyTorch", "framework", nil},
+			{"TensorFlow", "framework", nil},
+			{"AWS", "cloud", []string{"amazon web services"}},
+			{"GCP", "cloud", []string{"google cloud"}},
+			{"Azure", "cloud", []string{"microsoft azure"}},
+			{"Kubernetes", "cloud", []string{"k8s"}},
+			{"Terraform", "cloud", nil},
+			{"PostgreSQL", "db", []string{"postgres", "psql", "pg"}},
+			{"MySQL", "db", nil},
+			{"MongoDB", "db", []string{"mongo"}},
+			{"Redis", "db", nil},
+			{"ClickHouse", "db", nil},
+			{"Elasticsearch", "db", []string{"elastic"}},
+			{"Docker", "tool", nil},
+			{"Kafka", "tool", []string{"apache kafka"}},
+			{"RabbitMQ", "tool", []string{"rabbit"}},
+			{"Git", "tool", nil},
+			{"Linux", "tool", nil},
+			{"CI/CD", "tool", []string{"ci", "cicd"}},
+			{"GraphQL", "tool", nil},
+			{"gRPC", "tool", nil},
+			{"Figma", "tool", nil},
+		}
+
+		for _, s := range seed {
+			record := core.NewRecord(collection)
+			record.Set("name", s.name)
+			record.Set("category", s.category)
+			record.Set("aliases", s.aliases)
+			if err := app.Save(record); err != nil {
+				return err
+			}
+		}
+
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Skills as returned by the extractor, before normalization
+		jobs.Fields.Add(&core.JSONField{
+			Name: "rawSkills",
+		})
+
+		// Canonical skills for filtering and facets
+		jobs.Fields.Add(&core.RelationField{
+			Name:         "canonicalSkills",
+			CollectionId: collection.Id,
+			MaxSelect:    999,
+		})
+
+		return app.Save(jobs)
+	}, func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err == nil {
+			jobs.Fields.RemoveByName("rawSkills")
+			jobs.Fields.RemoveByName("canonicalSkills")
+			if err := app.Save(jobs); err != nil {
+				return err
+			}
+		}
+
+		collection, err := app.FindCollectionByNameOrId("skills")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,89 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("companies")
+		collection.ListRule = types.Pointer("@request.auth.id != ''")
+		collection.ViewRule = types.Pointer("@request.auth.id != ''")
+
+		// Display name as first seen, e.g. "Yandex LLC"
+		collection.Fields.Add(&core.TextField{
+			Name:     "name",
+			Required: true,
+		})
+
+		// Normalized name used for matching, see core.CompanyKey
+		collection.Fields.Add(&core.TextField{
+			Name:     "key",
+			Required: true,
+		})
+
+		// Other normalized spellings resolving to this company
+		collection.Fields.Add(&core.JSONField{
+			Name: "aliases",
+		})
+
+		// Telegram usernames of recruiters and HR
+		collection.Fields.Add(&core.JSONField{
+			Name: "contacts",
+		})
+
+		collection.Fields.Add(&core.JSONField{
+			Name: "websites",
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		collection.AddIndex("idx_companies_key", true, "key", "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Deduplicated company; the company text field keeps the name as posted
+		jobs.Fields.Add(&core.RelationField{
+			Name:         "employer",
+			CollectionId: collection.Id,
+			MaxSelect:    1,
+		})
+
+		jobs.AddIndex("idx_jobs_employer", false, "employer", "")
+
+		return app.Save(jobs)
+	}, func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err == nil {
+			jobs.RemoveIndex("idx_jobs_employer")
+			jobs.Fields.RemoveByName("employer")
+			if err := app.Save(jobs); err != nil {
+				return err
+			}
+		}
+
+		collection, err := app.FindCollectionByNameOrId("companies")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// How to apply: [{kind, value, link}] with tg://, mailto: and tel: deep links
+		collection.Fields.Add(&core.JSONField{
+			Name: "contacts",
+		})
+
+		// Contacts from the message entities (mentions, links, emails), merged
+		// into contacts on every extraction
+		collection.Fields.Add(&core.JSONField{
+			Name: "messageContacts",
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		collection.Fields.RemoveByName("contacts")
+		collection.Fields.RemoveByName("messageContacts")
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Position of the vacancy within a multi-vacancy post. 0 is the job
+		// of the message itself, siblings share its channelId/messageId.
+		jobs.Fields.Add(&core.NumberField{
+			Name:    "subIndex",
+			OnlyInt: true,
+			Min:     types.Pointer(0.0),
+		})
+
+		if err := app.Save(jobs); err != nil {
+			return err
+		}
+
+		cache, err := app.FindCollectionByNameOrId("extractionCache")
+		if err != nil {
+			return err
+		}
+
+		// Further vacancies of multi-vacancy posts
+		cache.Fields.Add(&core.JSONField{
+			Name: "siblings",
+		})
+
+		return app.Save(cache)
+	}, func(app core.App) error {
+		if cache, err := app.FindCollectionByNameOrId("extractionCache"); err == nil {
+			cache.Fields.RemoveByName("siblings")
+			if err := app.Save(cache); err != nil {
+				return err
+			}
+		}
+
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		jobs.Fields.RemoveByName("subIndex")
+
+		return app.Save(jobs)
+	})
+}
@@ -0,0 +1,111 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection("job_sightings")
+		collection.ListRule = types.Pointer("@request.auth.id != ''")
+		collection.ViewRule = types.Pointer("@request.auth.id != ''")
+
+		// Canonical job the posted text was first collected as
+		collection.Fields.Add(&core.RelationField{
+			Name:          "job",
+			CollectionId:  jobs.Id,
+			MaxSelect:     1,
+			Required:      true,
+			CascadeDelete: true,
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name:     "channelId",
+			Required: true,
+		})
+
+		collection.Fields.Add(&core.NumberField{
+			Name:    "messageId",
+			OnlyInt: true,
+		})
+
+		collection.Fields.Add(&core.URLField{
+			Name: "url",
+		})
+
+		// When the message was posted
+		collection.Fields.Add(&core.DateField{
+			Name: "seenAt",
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		collection.AddIndex("idx_job_sightings_message", true, "channelId, messageId", "")
+		collection.AddIndex("idx_job_sightings_job_seenAt", false, "job, seenAt", "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// Repost counters, kept in sync with job_sightings
+		jobs.Fields.Add(&core.NumberField{
+			Name:    "seenCount",
+			OnlyInt: true,
+			Min:     types.Pointer(0.0),
+		})
+
+		jobs.Fields.Add(&core.DateField{
+			Name: "firstSeen",
+		})
+
+		jobs.Fields.Add(&core.DateField{
+			Name: "lastSeen",
+		})
+
+		jobs.AddIndex("idx_jobs_hash", false, "hash", "")
+
+		if err := app.Save(jobs); err != nil {
+			return err
+		}
+
+		// Existing jobs were seen once, when collected
+		if _, err := app.DB().NewQuery(
+			"INSERT OR IGNORE INTO job_sightings (id, job, channelId, messageId, url, seenAt, created) " +
+				"SELECT substr(lower(hex(randomblob(8))), 1, 15), id, channelId, messageId, url, created, created " +
+				"FROM jobs WHERE subIndex = 0",
+		).Execute(); err != nil {
+			return err
+		}
+
+		_, err = app.DB().NewQuery(
+			"UPDATE jobs SET seenCount = 1, firstSeen = created, lastSeen = created",
+		).Execute()
+		return err
+	}, func(app core.App) error {
+		if jobs, err := app.FindCollectionByNameOrId("jobs"); err == nil {
+			jobs.RemoveIndex("idx_jobs_hash")
+			jobs.Fields.RemoveByName("seenCount")
+			jobs.Fields.RemoveByName("firstSeen")
+			jobs.Fields.RemoveByName("lastSeen")
+			if err := app.Save(jobs); err != nil {
+				return err
+			}
+		}
+
+		collection, err := app.FindCollectionByNameOrId("job_sightings")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(collection)
+	})
+}
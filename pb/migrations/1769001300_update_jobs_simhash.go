@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// simhashBands mirrors core.SimHashBands of the job module.
+const simhashBands = 8
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// SimHash fingerprint of originalText as 16 hex digits, see core.SimHash.
+		// Filled for existing jobs by `jobs fingerprint`.
+		jobs.Fields.Add(&core.TextField{
+			Name: "simhash",
+			Max:  16,
+		})
+
+		// One index per 8-bit band: near-duplicates share at least one band
+		for i := 0; i < simhashBands; i++ {
+			jobs.AddIndex(
+				fmt.Sprintf("idx_jobs_simhash_%d", i),
+				false,
+				fmt.Sprintf("substr(`simhash`, %d, 2)", 2*i+1),
+				"",
+			)
+		}
+
+		if err := app.Save(jobs); err != nil {
+			return err
+		}
+
+		sightings, err := app.FindCollectionByNameOrId("job_sightings")
+		if err != nil {
+			return err
+		}
+
+		// Differing SimHash bits from the job's text, 0 for exact reposts
+		sightings.Fields.Add(&core.NumberField{
+			Name:    "distance",
+			OnlyInt: true,
+		})
+
+		return app.Save(sightings)
+	}, func(app core.App) error {
+		if sightings, err := app.FindCollectionByNameOrId("job_sightings"); err == nil {
+			sightings.Fields.RemoveByName("distance")
+			if err := app.Save(sightings); err != nil {
+				return err
+			}
+		}
+
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		for i := 0; i < simhashBands; i++ {
+			jobs.RemoveIndex(fmt.Sprintf("idx_jobs_simhash_%d", i))
+		}
+		jobs.Fields.RemoveByName("simhash")
+
+		return app.Save(jobs)
+	})
+}
@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Runs before the unique message index, so the copies it deletes hand
+		// their sightings over to the job that stays. Databases that already
+		// have the index hold no copies and are left untouched.
+		//
+		// Messages collected twice by concurrent deliveries, each paired with
+		// the oldest job of the message
+		var pairs []struct {
+			Dup  string `db:"dup"`
+			Kept string `db:"kept"`
+		}
+		err := app.DB().NewQuery(
+			"SELECT j.id AS dup, (" +
+				"SELECT o.id FROM jobs o WHERE o.channelId = j.channelId AND o.messageId = j.messageId " +
+				"AND o.subIndex = j.subIndex ORDER BY o.created, o.id LIMIT 1) AS kept " +
+				"FROM jobs j WHERE EXISTS (" +
+				"SELECT 1 FROM jobs o WHERE o.channelId = j.channelId AND o.messageId = j.messageId " +
+				"AND o.subIndex = j.subIndex AND (o.created < j.created OR (o.created = j.created AND o.id < j.id)))",
+		).All(&pairs)
+		if err != nil {
+			return err
+		}
+
+		for _, pair := range pairs {
+			// Reposts linked to the copy become sightings of the kept job
+			result, err := app.DB().NewQuery(
+				"UPDATE job_sightings SET job = {:kept} WHERE job = {:dup}",
+			).Bind(dbx.Params{"kept": pair.Kept, "dup": pair.Dup}).Execute()
+			if err != nil {
+				return err
+			}
+			if moved, _ := result.RowsAffected(); moved == 0 {
+				continue
+			}
+
+			_, err = app.DB().NewQuery(
+				"UPDATE jobs SET " +
+					"seenCount = (SELECT COUNT(*) FROM job_sightings WHERE job = {:kept}), " +
+					"firstSeen = (SELECT COALESCE(MIN(seenAt), '') FROM job_sightings WHERE job = {:kept}), " +
+					"lastSeen = (SELECT COALESCE(MAX(seenAt), '') FROM job_sightings WHERE job = {:kept}) " +
+					"WHERE id = {:kept}",
+			).Bind(dbx.Params{"kept": pair.Kept}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		// Which job a sighting was linked to before isn't recorded
+		return nil
+	})
+}
@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Messages collected twice by concurrent deliveries: keep the oldest
+		// job, deleting the others through the app so relations cascade
+		var dupes []string
+		err := app.DB().NewQuery(
+			"SELECT id FROM jobs j WHERE EXISTS (" +
+				"SELECT 1 FROM jobs o WHERE o.channelId = j.channelId AND o.messageId = j.messageId " +
+				"AND o.subIndex = j.subIndex AND (o.created < j.created OR (o.created = j.created AND o.id < j.id)))",
+		).Column(&dupes)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range dupes {
+			record, err := app.FindRecordById("jobs", id)
+			if err != nil {
+				continue
+			}
+			if err := app.Delete(record); err != nil {
+				return err
+			}
+		}
+
+		// Same text collected from different messages: the newer jobs stay,
+		// but only the oldest one keeps the hash reposts are matched by
+		_, err = app.DB().NewQuery(
+			"UPDATE jobs SET hash = '' WHERE subIndex = 0 AND hash != '' AND EXISTS (" +
+				"SELECT 1 FROM jobs o WHERE o.subIndex = 0 AND o.hash = jobs.hash " +
+				"AND (o.created < jobs.created OR (o.created = jobs.created AND o.id < jobs.id)))",
+		).Execute()
+		if err != nil {
+			return err
+		}
+
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Siblings of a multi-vacancy post share the message and the text
+		jobs.AddIndex("idx_jobs_message", true, "channelId, messageId, subIndex", "")
+		jobs.RemoveIndex("idx_jobs_hash")
+		jobs.AddIndex("idx_jobs_hash", true, "hash", "subIndex = 0 AND hash != ''")
+
+		return app.Save(jobs)
+	}, func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		jobs.RemoveIndex("idx_jobs_message")
+		jobs.AddIndex("idx_jobs_hash", false, "hash", "")
+
+		return app.Save(jobs)
+	})
+}
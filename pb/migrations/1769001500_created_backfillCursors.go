@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("backfillCursors")
+
+		// Channel username as passed to tg-backfill, without @
+		collection.Fields.Add(&core.TextField{
+			Name:     "channel",
+			Required: true,
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name: "channelId",
+		})
+
+		// Oldest message handled so far; history is read newest first
+		collection.Fields.Add(&core.NumberField{
+			Name:    "offsetId",
+			OnlyInt: true,
+		})
+
+		// Bounds the backfill stops at
+		collection.Fields.Add(&core.DateField{
+			Name: "until",
+		})
+
+		collection.Fields.Add(&core.NumberField{
+			Name:    "untilId",
+			OnlyInt: true,
+		})
+
+		// Number of messages handled
+		collection.Fields.Add(&core.NumberField{
+			Name:    "messages",
+			OnlyInt: true,
+		})
+
+		collection.Fields.Add(&core.BoolField{
+			Name: "done",
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name: "lastError",
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		collection.AddIndex("idx_backfillCursors_channel", true, "channel", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("backfillCursors")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,104 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Common update state per Telegram account
+		states := core.NewBaseCollection("tgStates")
+
+		states.Fields.Add(&core.TextField{
+			Name:     "userId",
+			Required: true,
+		})
+
+		states.Fields.Add(&core.NumberField{
+			Name:    "pts",
+			OnlyInt: true,
+		})
+
+		states.Fields.Add(&core.NumberField{
+			Name:    "qts",
+			OnlyInt: true,
+		})
+
+		states.Fields.Add(&core.NumberField{
+			Name:    "date",
+			OnlyInt: true,
+		})
+
+		states.Fields.Add(&core.NumberField{
+			Name:    "seq",
+			OnlyInt: true,
+		})
+
+		states.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		states.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		states.AddIndex("idx_tgStates_user", true, "userId", "")
+
+		if err := app.Save(states); err != nil {
+			return err
+		}
+
+		// Update state per channel of an account
+		channels := core.NewBaseCollection("tgChannelStates")
+
+		channels.Fields.Add(&core.TextField{
+			Name:     "userId",
+			Required: true,
+		})
+
+		channels.Fields.Add(&core.TextField{
+			Name:     "channelId",
+			Required: true,
+		})
+
+		// 0 until known, and again after the common state is reset
+		channels.Fields.Add(&core.NumberField{
+			Name:    "pts",
+			OnlyInt: true,
+		})
+
+		channels.Fields.Add(&core.TextField{
+			Name: "accessHash",
+		})
+
+		channels.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		channels.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		channels.AddIndex("idx_tgChannelStates_channel", true, "userId, channelId", "")
+
+		return app.Save(channels)
+	}, func(app core.App) error {
+		for _, name := range []string{"tgChannelStates", "tgStates"} {
+			collection, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				continue
+			}
+			if err := app.Delete(collection); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,92 @@
+package migrations
+
+import (
+	"slices"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Vacancies their poster marked as taken by editing the post
+		if status, ok := jobs.Fields.GetByName("status").(*core.SelectField); ok {
+			if !slices.Contains(status.Values, "closed") {
+				status.Values = append(status.Values, "closed")
+			}
+		}
+
+		jobs.Fields.Add(&core.DateField{
+			Name: "closedAt",
+		})
+
+		// When the message was last edited
+		jobs.Fields.Add(&core.DateField{
+			Name: "editedAt",
+		})
+
+		if err := app.Save(jobs); err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection("jobRevisions")
+
+		collection.Fields.Add(&core.RelationField{
+			Name:          "job",
+			CollectionId:  jobs.Id,
+			MaxSelect:     1,
+			Required:      true,
+			CascadeDelete: true,
+		})
+
+		// Text and hash of the job before the edit
+		collection.Fields.Add(&core.TextField{
+			Name: "originalText",
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name: "hash",
+		})
+
+		// When the text was replaced
+		collection.Fields.Add(&core.DateField{
+			Name: "editedAt",
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		collection.AddIndex("idx_jobRevisions_job_editedAt", false, "job, editedAt", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		if collection, err := app.FindCollectionByNameOrId("jobRevisions"); err == nil {
+			if err := app.Delete(collection); err != nil {
+				return err
+			}
+		}
+
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		if status, ok := jobs.Fields.GetByName("status").(*core.SelectField); ok {
+			status.Values = slices.DeleteFunc(status.Values, func(v string) bool {
+				return v == "closed"
+			})
+		}
+
+		jobs.Fields.RemoveByName("closedAt")
+		jobs.Fields.RemoveByName("editedAt")
+
+		return app.Save(jobs)
+	})
+}
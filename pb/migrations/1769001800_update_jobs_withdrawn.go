@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"slices"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Vacancies whose posts were all deleted, kept for analytics
+		if status, ok := jobs.Fields.GetByName("status").(*core.SelectField); ok {
+			if !slices.Contains(status.Values, "withdrawn") {
+				status.Values = append(status.Values, "withdrawn")
+			}
+		}
+
+		jobs.Fields.Add(&core.DateField{
+			Name: "withdrawnAt",
+		})
+
+		if err := app.Save(jobs); err != nil {
+			return err
+		}
+
+		sightings, err := app.FindCollectionByNameOrId("job_sightings")
+		if err != nil {
+			return err
+		}
+
+		// When the message was deleted from the channel
+		sightings.Fields.Add(&core.DateField{
+			Name: "deletedAt",
+		})
+
+		return app.Save(sightings)
+	}, func(app core.App) error {
+		if sightings, err := app.FindCollectionByNameOrId("job_sightings"); err == nil {
+			sightings.Fields.RemoveByName("deletedAt")
+			if err := app.Save(sightings); err != nil {
+				return err
+			}
+		}
+
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		if status, ok := jobs.Fields.GetByName("status").(*core.SelectField); ok {
+			status.Values = slices.DeleteFunc(status.Values, func(v string) bool {
+				return v == "withdrawn"
+			})
+		}
+
+		jobs.Fields.RemoveByName("withdrawnAt")
+
+		return app.Save(jobs)
+	})
+}
@@ -0,0 +1,131 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Chats the collector ingests. Superuser only, managed in the admin UI
+		// and with the tg-sources command.
+		collection := core.NewBaseCollection("sources")
+
+		collection.Fields.Add(&core.TextField{
+			Name:     "peerId",
+			Required: true,
+		})
+
+		collection.Fields.Add(&core.SelectField{
+			Name:      "type",
+			MaxSelect: 1,
+			Values:    []string{"channel", "supergroup", "group", "bot"},
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name: "title",
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name: "username",
+		})
+
+		collection.Fields.Add(&core.BoolField{
+			Name: "enabled",
+		})
+
+		// Registered automatically on first message, never reviewed
+		collection.Fields.Add(&core.BoolField{
+			Name: "pending",
+		})
+
+		// Higher priority jobs are extracted first
+		collection.Fields.Add(&core.NumberField{
+			Name:    "priority",
+			OnlyInt: true,
+		})
+
+		// Keyword filter overrides: {"bypass", "minLength", "blacklist"}
+		collection.Fields.Add(&core.JSONField{
+			Name: "filter",
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name: "addedBy",
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		collection.AddIndex("idx_sources_peer", true, "peerId", "")
+		collection.AddIndex("idx_sources_username", false, "username", "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// Priority of the source, copied to jobs and their queue entries
+		for _, name := range []string{"jobs", "jobQueue"} {
+			target, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				return err
+			}
+
+			target.Fields.Add(&core.NumberField{
+				Name:    "priority",
+				OnlyInt: true,
+			})
+
+			if err := app.Save(target); err != nil {
+				return err
+			}
+		}
+
+		// Chats collected before the registry keep being collected
+		var peerIDs []string
+		err := app.DB().
+			NewQuery("SELECT channelId FROM jobs WHERE channelId != '' UNION SELECT channelId FROM job_sightings WHERE channelId != ''").
+			Column(&peerIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, peerID := range peerIDs {
+			source := core.NewRecord(collection)
+			source.Set("peerId", peerID)
+			source.Set("enabled", true)
+			source.Set("addedBy", "collector")
+
+			if err := app.Save(source); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		for _, name := range []string{"jobs", "jobQueue"} {
+			target, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				continue
+			}
+			target.Fields.RemoveByName("priority")
+			if err := app.Save(target); err != nil {
+				return err
+			}
+		}
+
+		collection, err := app.FindCollectionByNameOrId("sources")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(collection)
+	})
+}
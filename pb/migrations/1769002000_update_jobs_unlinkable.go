@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Posts of legacy groups and bot chats have no link, url is empty
+		jobs.Fields.Add(&core.BoolField{
+			Name: "unlinkable",
+		})
+
+		return app.Save(jobs)
+	}, func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		jobs.Fields.RemoveByName("unlinkable")
+
+		return app.Save(jobs)
+	})
+}
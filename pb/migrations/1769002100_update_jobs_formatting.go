@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// The post as shown in Telegram: escaped text with formatting and
+		// links, safe to display. Tags add up to several times the text.
+		jobs.Fields.Add(&core.TextField{
+			Name: "originalHtml",
+			Max:  50000,
+		})
+
+		// Original text with hidden links written out, extracted from
+		jobs.Fields.Add(&core.TextField{
+			Name: "expandedText",
+			Max:  20000,
+		})
+
+		return app.Save(jobs)
+	}, func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		jobs.Fields.RemoveByName("originalHtml")
+		jobs.Fields.RemoveByName("expandedText")
+
+		return app.Save(jobs)
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Document the vacancy was posted as (PDF, DOCX, TXT). Not limited
+		// by MIME type: a rejected file would drop the whole job.
+		jobs.Fields.Add(&core.FileField{
+			Name:      "attachment",
+			MaxSelect: 1,
+			MaxSize:   100 << 20,
+		})
+
+		return app.Save(jobs)
+	}, func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		jobs.Fields.RemoveByName("attachment")
+
+		return app.Save(jobs)
+	})
+}
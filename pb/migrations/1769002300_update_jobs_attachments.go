@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Every document of an album, Telegram allows up to 10 per album
+		field, ok := jobs.Fields.GetByName("attachment").(*core.FileField)
+		if !ok {
+			return nil
+		}
+		field.Name = "attachments"
+		field.MaxSelect = 10
+
+		return app.Save(jobs)
+	}, func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		field, ok := jobs.Fields.GetByName("attachments").(*core.FileField)
+		if !ok {
+			return nil
+		}
+		field.Name = "attachment"
+		field.MaxSelect = 1
+
+		return app.Save(jobs)
+	})
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Job the text is a near-duplicate of. The job is extracted and
+		// folded into it as a sighting if both are the same vacancy.
+		jobs.Fields.Add(&core.RelationField{
+			Name:         "repostOf",
+			CollectionId: jobs.Id,
+			MaxSelect:    1,
+		})
+
+		// Differing SimHash bits from the text of repostOf
+		jobs.Fields.Add(&core.NumberField{
+			Name:    "repostDistance",
+			OnlyInt: true,
+		})
+
+		return app.Save(jobs)
+	}, func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		jobs.Fields.RemoveByName("repostOf")
+		jobs.Fields.RemoveByName("repostDistance")
+
+		return app.Save(jobs)
+	})
+}
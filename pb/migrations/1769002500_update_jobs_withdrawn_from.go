@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Status a withdrawn job had, restored when it is reposted
+		jobs.Fields.Add(&core.SelectField{
+			Name:      "withdrawnFrom",
+			MaxSelect: 1,
+			Values:    []string{"raw", "processed", "failed"},
+		})
+
+		if err := app.Save(jobs); err != nil {
+			return err
+		}
+
+		// Jobs withdrawn so far were extracted if they have an extractor
+		_, err = app.DB().NewQuery(`
+			UPDATE jobs
+			SET withdrawnFrom = CASE WHEN extractor != '' THEN 'processed' ELSE 'raw' END
+			WHERE status = 'withdrawn'
+		`).Execute()
+		return err
+	}, func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		jobs.Fields.RemoveByName("withdrawnFrom")
+
+		return app.Save(jobs)
+	})
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// sourcePostURL is Source.PostURL in SQL for rows of the table given as
+// the format argument, with their source joined as s.
+const sourcePostURL = `CASE
+	WHEN s.type IN ('group', 'bot') THEN ''
+	WHEN s.username != '' THEN 'https://t.me/' || s.username || '/' || CAST(%[1]s.messageId AS INTEGER)
+	ELSE 'https://t.me/c/' || s.peerId || '/' || CAST(%[1]s.messageId AS INTEGER)
+END`
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Jobs and sightings collected before the sources registry link
+		// public chats by id; rebuild their links from the registry.
+		_, err := app.DB().NewQuery(fmt.Sprintf(`
+			UPDATE jobs SET
+				url = (SELECT %s FROM sources s WHERE s.peerId = jobs.channelId LIMIT 1),
+				unlinkable = (SELECT s.type IN ('group', 'bot') FROM sources s WHERE s.peerId = jobs.channelId LIMIT 1)
+			WHERE channelId IN (SELECT peerId FROM sources)
+		`, fmt.Sprintf(sourcePostURL, "jobs"))).Execute()
+		if err != nil {
+			return err
+		}
+
+		_, err = app.DB().NewQuery(fmt.Sprintf(`
+			UPDATE job_sightings SET
+				url = (SELECT %s FROM sources s WHERE s.peerId = job_sightings.channelId LIMIT 1)
+			WHERE channelId IN (SELECT peerId FROM sources)
+		`, fmt.Sprintf(sourcePostURL, "job_sightings"))).Execute()
+		return err
+	}, func(app core.App) error {
+		// Links by username stay valid
+		return nil
+	})
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Telegram ids of the stored attachments; an edit keeping them
+		// doesn't download and store them again
+		jobs.Fields.Add(&core.JSONField{
+			Name: "attachmentIds",
+		})
+
+		// Text read from the attachments, the end of originalText
+		jobs.Fields.Add(&core.TextField{
+			Name: "documentText",
+		})
+
+		return app.Save(jobs)
+	}, func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		jobs.Fields.RemoveByName("attachmentIds")
+		jobs.Fields.RemoveByName("documentText")
+
+		return app.Save(jobs)
+	})
+}
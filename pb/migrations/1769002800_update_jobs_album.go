@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return err
+		}
+
+		// Telegram grouped id of the album the post was sent as
+		jobs.Fields.Add(&core.TextField{
+			Name: "albumId",
+		})
+
+		// Messages of the album still posted, so that edits and deletions
+		// of any part find the job
+		jobs.Fields.Add(&core.JSONField{
+			Name: "albumMessageIds",
+		})
+
+		jobs.AddIndex("idx_jobs_album", false, "channelId, albumId", "albumId != ''")
+
+		return app.Save(jobs)
+	}, func(app core.App) error {
+		jobs, err := app.FindCollectionByNameOrId("jobs")
+		if err != nil {
+			return nil
+		}
+
+		jobs.RemoveIndex("idx_jobs_album")
+		jobs.Fields.RemoveByName("albumId")
+		jobs.Fields.RemoveByName("albumMessageIds")
+
+		return app.Save(jobs)
+	})
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("pendingAlbums")
+
+		// Chat and Telegram grouped id of an album whose parts are buffered
+		collection.Fields.Add(&core.TextField{
+			Name:     "peerId",
+			Required: true,
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name:     "albumId",
+			Required: true,
+		})
+
+		// Parts received so far
+		collection.Fields.Add(&core.JSONField{
+			Name: "messageIds",
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		collection.AddIndex("idx_pendingAlbums_album", true, "peerId, albumId", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("pendingAlbums")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,97 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		cache, err := app.FindCollectionByNameOrId("extractionCache")
+		if err != nil {
+			return err
+		}
+
+		// Entries are looked up by text and prompt, then picked by model
+		cache.AddIndex("idx_extractionCache_textHash", false, "textHash, prompt", "")
+		if err := app.Save(cache); err != nil {
+			return err
+		}
+
+		records, err := app.FindAllRecords(cache)
+		if err != nil {
+			return err
+		}
+
+		// Entries were keyed by the whole model chain: key them by the model
+		// that answered instead, keeping one entry per model. Entries whose
+		// key doesn't change claim it first.
+		keys := make(map[*core.Record]string, len(records))
+		seen := make(map[string]bool)
+		for _, record := range records {
+			var provenance struct {
+				Extractor string `json:"extractor"`
+				Model     string `json:"model"`
+			}
+			if err := record.UnmarshalJSONField("provenance", &provenance); err != nil || provenance.Model == "" {
+				continue
+			}
+
+			model := provenance.Extractor + ":" + provenance.Model
+			sum := sha256.Sum256([]byte(record.GetString("textHash") + "|" + model + "|" + record.GetString("prompt")))
+			keys[record] = hex.EncodeToString(sum[:])
+
+			record.Set("model", model)
+			if keys[record] == record.GetString("cacheKey") {
+				seen[keys[record]] = true
+			}
+		}
+
+		var rekeyed []*core.Record
+		for _, record := range records {
+			key, ok := keys[record]
+			if key == record.GetString("cacheKey") {
+				if err := app.Save(record); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if !ok || seen[key] {
+				if err := app.Delete(record); err != nil {
+					return err
+				}
+				continue
+			}
+			seen[key] = true
+
+			// Moved aside first, the new key may still be another's old one
+			rekeyed = append(rekeyed, record)
+			record.Set("cacheKey", record.Id)
+			if err := app.Save(record); err != nil {
+				return err
+			}
+		}
+
+		for _, record := range rekeyed {
+			record.Set("cacheKey", keys[record])
+			if err := app.Save(record); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		cache, err := app.FindCollectionByNameOrId("extractionCache")
+		if err != nil {
+			return nil
+		}
+
+		// Entries keep their per-model keys, which lookups by chain miss
+		cache.RemoveIndex("idx_extractionCache_textHash")
+		return app.Save(cache)
+	})
+}
@@ -0,0 +1,97 @@
+package in
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"svpb-tmpl/pkg/collector/core"
+)
+
+// albumWindow is how long the parts of an album are waited for after the
+// last one arrived. Telegram delivers them together, usually in one batch.
+const albumWindow = time.Second
+
+// maxAlbumSize is the number of messages an album holds at most.
+const maxAlbumSize = 10
+
+type albumKey struct {
+	peerID  int64
+	albumID int64
+}
+
+// album is a media album whose parts are being collected.
+type album struct {
+	ctx   context.Context
+	parts []core.Message
+	timer *time.Timer
+}
+
+// albums buffers the messages of media albums and hands each album over as
+// one merged message once no more parts arrive.
+type albums struct {
+	mu      sync.Mutex
+	pending map[albumKey]*album
+	window  time.Duration
+	handle  func(ctx context.Context, msg core.Message)
+}
+
+func newAlbums(window time.Duration, handle func(ctx context.Context, msg core.Message)) *albums {
+	return &albums{
+		pending: make(map[albumKey]*album),
+		window:  window,
+		handle:  handle,
+	}
+}
+
+// add buffers a part of an album. Parts delivered twice are ignored.
+func (a *albums) add(ctx context.Context, msg core.Message) {
+	key := albumKey{peerID: msg.ChannelID, albumID: msg.AlbumID}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	buffered, ok := a.pending[key]
+	if !ok {
+		buffered = &album{}
+		buffered.timer = time.AfterFunc(a.window, func() { a.flush(key) })
+		a.pending[key] = buffered
+	} else {
+		buffered.timer.Reset(a.window)
+	}
+
+	if slices.ContainsFunc(buffered.parts, func(part core.Message) bool { return part.MessageID == msg.MessageID }) {
+		return
+	}
+	// Handled after the update that delivered the last part is done
+	buffered.ctx = context.WithoutCancel(ctx)
+	buffered.parts = append(buffered.parts, msg)
+}
+
+// flush hands a buffered album over.
+func (a *albums) flush(key albumKey) {
+	a.mu.Lock()
+	buffered, ok := a.pending[key]
+	delete(a.pending, key)
+	a.mu.Unlock()
+
+	if ok {
+		a.handle(buffered.ctx, core.MergeAlbum(buffered.parts))
+	}
+}
+
+// flushAll hands all buffered albums over without waiting, e.g. on shutdown.
+func (a *albums) flushAll() {
+	a.mu.Lock()
+	keys := make([]albumKey, 0, len(a.pending))
+	for key, buffered := range a.pending {
+		buffered.timer.Stop()
+		keys = append(keys, key)
+	}
+	a.mu.Unlock()
+
+	for _, key := range keys {
+		a.flush(key)
+	}
+}
@@ -0,0 +1,136 @@
+package in
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"svpb-tmpl/pkg/collector/core"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"go.uber.org/zap"
+)
+
+// API handles HTTP requests for collector module.
+type API struct {
+	service    core.CollectorService
+	backfiller core.Backfiller
+	cursors    core.BackfillCursors
+	logger     *zap.Logger
+
+	backfilling atomic.Bool
+
+	// Background backfills are cancelled and waited for on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewAPI creates a new API adapter.
+func NewAPI(
+	service core.CollectorService,
+	backfiller core.Backfiller,
+	cursors core.BackfillCursors,
+	logger *zap.Logger,
+) *API {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &API{
+		service:    service,
+		backfiller: backfiller,
+		cursors:    cursors,
+		logger:     logger,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Register registers all HTTP routes on the PocketBase app.
+func (a *API) Register(app *pocketbase.PocketBase) {
+	app.OnServe().BindFunc(func(se *pbcore.ServeEvent) error {
+		se.Router.GET("/api/collector/stats", a.handleStats).Bind(apis.RequireSuperuserAuth())
+		se.Router.GET("/api/collector/backfill", a.handleBackfillStatus).Bind(apis.RequireSuperuserAuth())
+		se.Router.POST("/api/collector/backfill", a.handleBackfill).Bind(apis.RequireSuperuserAuth())
+		return se.Next()
+	})
+
+	app.OnTerminate().BindFunc(func(e *pbcore.TerminateEvent) error {
+		a.cancel()
+		a.wg.Wait()
+		return e.Next()
+	})
+}
+
+// handleStats reports how many messages were collected, skipped as
+// duplicates or recorded as reposts since start.
+func (a *API) handleStats(e *pbcore.RequestEvent) error {
+	return e.JSON(200, a.service.Stats())
+}
+
+type backfillRequest struct {
+	Channels []string `json:"channels"`
+	// Until is a YYYY-MM-DD date
+	Until   string `json:"until"`
+	UntilID int    `json:"untilId"`
+	Restart bool   `json:"restart"`
+}
+
+// handleBackfill starts a backfill of the given channels in the background.
+// Progress is reported by handleBackfillStatus.
+func (a *API) handleBackfill(e *pbcore.RequestEvent) error {
+	var body backfillRequest
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid request body", err)
+	}
+	if len(body.Channels) == 0 {
+		return e.BadRequestError("channels required", nil)
+	}
+
+	req := core.BackfillRequest{UntilID: body.UntilID, Restart: body.Restart}
+	if body.Until != "" {
+		until, err := time.Parse("2006-01-02", body.Until)
+		if err != nil {
+			return e.BadRequestError("until must be YYYY-MM-DD", err)
+		}
+		req.Until = until
+	}
+
+	if !a.backfilling.CompareAndSwap(false, true) {
+		return e.Error(409, core.ErrBackfillRunning.Error(), nil)
+	}
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		defer a.backfilling.Store(false)
+
+		for _, channel := range body.Channels {
+			req.Channel = channel
+			if _, err := a.backfiller.Backfill(a.ctx, req); err != nil {
+				a.logger.Error("Backfill failed", zap.String("channel", channel), zap.Error(err))
+				if errors.Is(err, core.ErrBackfillRunning) || errors.Is(err, core.ErrSessionInUse) || a.ctx.Err() != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return e.JSON(202, map[string]any{"channels": body.Channels})
+}
+
+// handleBackfillStatus lists the saved cursors of all backfills.
+func (a *API) handleBackfillStatus(e *pbcore.RequestEvent) error {
+	cursors, err := a.cursors.List(e.Request.Context())
+	if err != nil {
+		return e.InternalServerError("Failed to list backfills", err)
+	}
+
+	return e.JSON(200, map[string]any{
+		"running": a.backfilling.Load(),
+		"cursors": cursors,
+	})
+}
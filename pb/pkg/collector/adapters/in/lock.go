@@ -0,0 +1,34 @@
+//go:build unix
+
+package in
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	"svpb-tmpl/pkg/collector/core"
+)
+
+// lockSession takes an exclusive lock next to the session file, so that two
+// processes never run a client on the same session. The lock is released
+// by the returned func or when the process exits.
+func lockSession(sessionPath string) (func(), error) {
+	file, err := os.OpenFile(sessionPath+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, core.ErrSessionInUse
+		}
+		return nil, err
+	}
+
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package in
+
+// lockSession is a no-op where file locks aren't supported.
+func lockSession(sessionPath string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,210 @@
+package in
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"svpb-tmpl/pkg/collector/core"
+
+	"github.com/gotd/td/tg"
+	"github.com/spf13/cobra"
+)
+
+// sourcesCommand builds `tg-sources list|add|enable|disable|set`.
+func (t *TGAdapter) sourcesCommand() *cobra.Command {
+	sourcesCmd := &cobra.Command{
+		Use:   "tg-sources",
+		Short: "Manage the chats the collector ingests",
+		Long:  "Chats are registered disabled and pending review when the collector first sees a message from them. Enable the ones posting vacancies; messages of disabled chats are ignored.",
+	}
+
+	var pendingOnly bool
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show registered sources, highest priority first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sources, err := t.sources.List(cmd.Context(), pendingOnly)
+			if err != nil {
+				return err
+			}
+
+			for _, source := range sources {
+				fmt.Printf("%-14d %-10s %-8s %4d  %s\n", source.PeerID, source.Type, sourceState(source), source.Priority, source.Label())
+			}
+			return nil
+		},
+	}
+	listCmd.Flags().BoolVar(&pendingOnly, "pending", false, "only show sources pending review")
+	sourcesCmd.AddCommand(listCmd)
+
+	var addPriority int
+	addCmd := &cobra.Command{
+		Use:     "add CHANNEL [CHANNEL...]",
+		Short:   "Register public channels by username and enable them",
+		Example: "  tg-sources add @golang_jobs t.me/remote_it --priority 10",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return t.withAPI(cmd.Context(), func(ctx context.Context, api *tg.Client) error {
+				for _, arg := range args {
+					source, err := t.addSource(ctx, api, arg, addPriority)
+					if err != nil {
+						return fmt.Errorf("%s: %w", arg, err)
+					}
+					fmt.Printf("Enabled %s (%d)\n", source.Label(), source.PeerID)
+				}
+				return nil
+			})
+		},
+	}
+	addCmd.Flags().IntVar(&addPriority, "priority", 0, "extraction priority, higher first")
+	sourcesCmd.AddCommand(addCmd)
+
+	sourcesCmd.AddCommand(&cobra.Command{
+		Use:     "enable SOURCE [SOURCE...]",
+		Short:   "Collect messages of sources, by peer id or username",
+		Example: "  tg-sources enable 1234567890 @golang_jobs",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return t.updateSources(cmd.Context(), args, func(source *core.Source) {
+				source.Enabled = true
+			})
+		},
+	})
+
+	sourcesCmd.AddCommand(&cobra.Command{
+		Use:     "disable SOURCE [SOURCE...]",
+		Short:   "Stop collecting messages of sources, by peer id or username",
+		Example: "  tg-sources disable @crypto_offtopic",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return t.updateSources(cmd.Context(), args, func(source *core.Source) {
+				source.Enabled = false
+			})
+		},
+	})
+
+	var (
+		priority  int
+		bypass    bool
+		minLength int
+		blacklist []string
+	)
+	setCmd := &cobra.Command{
+		Use:     "set SOURCE",
+		Short:   "Change the priority and keyword filter overrides of a source",
+		Example: "  tg-sources set @golang_jobs --priority 10 --bypass\n  tg-sources set 1234567890 --min-length 300 --blacklist стажировка,internship",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+			return t.updateSources(cmd.Context(), args, func(source *core.Source) {
+				if flags.Changed("priority") {
+					source.Priority = priority
+				}
+				if flags.Changed("bypass") {
+					source.Filter.Bypass = bypass
+				}
+				if flags.Changed("min-length") {
+					source.Filter.MinLength = minLength
+				}
+				if flags.Changed("blacklist") {
+					source.Filter.Blacklist = blacklist
+				}
+			})
+		},
+	}
+	setCmd.Flags().IntVar(&priority, "priority", 0, "extraction priority, higher first")
+	setCmd.Flags().BoolVar(&bypass, "bypass", false, "skip the keyword filter, for channels posting only vacancies")
+	setCmd.Flags().IntVar(&minLength, "min-length", 0, "minimum message length, 0 for the default")
+	setCmd.Flags().StringSliceVar(&blacklist, "blacklist", nil, "extra keywords dropping a message, empty to clear")
+	sourcesCmd.AddCommand(setCmd)
+
+	return sourcesCmd
+}
+
+// addSource registers a public channel as an enabled source.
+func (t *TGAdapter) addSource(ctx context.Context, api *tg.Client, channel string, priority int) (core.Source, error) {
+	username := core.ChannelUsername(channel)
+	if username == "" {
+		return core.Source{}, fmt.Errorf("channel username required")
+	}
+
+	resolved, err := resolveChannel(ctx, api, username)
+	if err != nil {
+		return core.Source{}, err
+	}
+	seen := *channelSource(resolved)
+
+	source, err := t.sources.Find(ctx, core.SourceRef{PeerID: seen.PeerID})
+	if errors.Is(err, core.ErrSourceNotFound) {
+		source = seen
+		source.AddedBy = core.AddedByCLI
+	} else if err != nil {
+		return core.Source{}, err
+	}
+
+	source.Type = seen.Type
+	source.Title = seen.Title
+	source.Username = seen.Username
+	source.Enabled = true
+	source.Pending = false
+	source.Priority = priority
+
+	return t.sources.Save(ctx, source)
+}
+
+// updateSources applies update to registered sources. Updated sources count
+// as reviewed.
+func (t *TGAdapter) updateSources(ctx context.Context, refs []string, update func(source *core.Source)) error {
+	for _, arg := range refs {
+		ref, err := core.ParseSourceRef(arg)
+		if err != nil {
+			return err
+		}
+
+		source, err := t.sources.Find(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+
+		update(&source)
+		source.Pending = false
+
+		if source, err = t.sources.Save(ctx, source); err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+		fmt.Printf("%s: %s, priority %d%s\n", source.Label(), sourceState(source), source.Priority, filterSummary(source.Filter))
+	}
+	return nil
+}
+
+// sourceState describes whether a source is collected.
+func sourceState(source core.Source) string {
+	switch {
+	case source.Pending:
+		return "pending"
+	case source.Enabled:
+		return "enabled"
+	default:
+		return "disabled"
+	}
+}
+
+// filterSummary describes the keyword filter overrides of a source.
+func filterSummary(filter core.SourceFilter) string {
+	var parts []string
+	if filter.Bypass {
+		parts = append(parts, "bypass")
+	}
+	if filter.MinLength > 0 {
+		parts = append(parts, fmt.Sprintf("min length %d", filter.MinLength))
+	}
+	if len(filter.Blacklist) > 0 {
+		parts = append(parts, "blacklist "+strings.Join(filter.Blacklist, ", "))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(parts, ", ")
+}
@@ -2,16 +2,26 @@ package in
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf16"
 
 	"svpb-tmpl/config"
 	"svpb-tmpl/pkg/collector/core"
 
 	"github.com/gotd/td/telegram"
 	"github.com/gotd/td/telegram/auth"
+	"github.com/gotd/td/telegram/downloader"
+	"github.com/gotd/td/telegram/updates"
+	updhook "github.com/gotd/td/telegram/updates/hook"
 	"github.com/gotd/td/tg"
 	"github.com/pocketbase/pocketbase"
 	"github.com/spf13/cobra"
@@ -23,22 +33,65 @@ type TGAdapter struct {
 	cfg        config.TelegramConfig
 	client     *telegram.Client
 	dispatcher tg.UpdateDispatcher
+	gaps       *updates.Manager
 	service    core.CollectorService
+	cursors    core.BackfillCursors
+	sources    core.SourceRegistry
+	state      UpdateState
+	pending    core.PendingAlbums
+	albums     *albums
 	logger     *zap.Logger
+
+	// running is set while Start keeps the client connected
+	running     atomic.Bool
+	selfID      atomic.Int64
+	backfilling sync.Mutex
+}
+
+// UpdateState persists the update state (pts, qts, date, seq and channel
+// pts) the client resumes from after downtime.
+type UpdateState interface {
+	updates.StateStorage
+	updates.ChannelAccessHasher
 }
 
 // NewTelegram creates a new Telegram adapter.
-func NewTG(cfg config.TelegramConfig, service core.CollectorService, logger *zap.Logger) *TGAdapter {
+func NewTG(
+	cfg config.TelegramConfig,
+	service core.CollectorService,
+	cursors core.BackfillCursors,
+	sources core.SourceRegistry,
+	state UpdateState,
+	pending core.PendingAlbums,
+	logger *zap.Logger,
+) *TGAdapter {
 	if logger == nil {
 		logger, _ = zap.NewDevelopment()
 	}
 
 	dispatcher := tg.NewUpdateDispatcher()
 
+	// Updates pass through the gap manager, which orders them by pts and
+	// fetches whatever was missed while offline or disconnected.
+	gaps := updates.New(updates.Config{
+		Handler:      dispatcher,
+		Storage:      state,
+		AccessHasher: state,
+		Logger:       logger.Named("updates"),
+		OnChannelTooLong: func(channelID int64) {
+			logger.Warn("Too many missed messages in channel, run tg-backfill to collect them",
+				zap.Int64("channel_id", channelID),
+			)
+		},
+	})
+
 	client := telegram.NewClient(cfg.APIID, cfg.APIHash, telegram.Options{
 		Logger:         logger,
 		SessionStorage: &telegram.FileSessionStorage{Path: cfg.SessionPath},
-		UpdateHandler:  dispatcher,
+		UpdateHandler:  gaps,
+		Middlewares: []telegram.Middleware{
+			updhook.UpdateHook(gaps.Handle),
+		},
 		Device: telegram.DeviceConfig{
 			DeviceModel:    "Desktop",
 			SystemVersion:  "Windows 10",
@@ -52,71 +105,604 @@ func NewTG(cfg config.TelegramConfig, service core.CollectorService, logger *zap
 		cfg:        cfg,
 		client:     client,
 		dispatcher: dispatcher,
+		gaps:       gaps,
 		service:    service,
+		cursors:    cursors,
+		sources:    sources,
+		state:      state,
+		pending:    pending,
 		logger:     logger,
 	}
 
+	adapter.albums = newAlbums(albumWindow, func(ctx context.Context, msg core.Message) {
+		if err := service.Handle(ctx, msg); err != nil {
+			logger.Error("Failed to handle album", zap.Int64("channelId", msg.ChannelID), zap.Error(err))
+		}
+		if err := pending.Remove(ctx, msg.ChannelID, msg.AlbumID); err != nil {
+			logger.Warn("Failed to remove pending album", zap.Int64("channelId", msg.ChannelID), zap.Error(err))
+		}
+	})
+
 	// Set up message handlers
 	adapter.setupHandlers()
 
 	return adapter
 }
 
-// setupHandlers registers Telegram update handlers.
+// setupHandlers registers Telegram update handlers. Only messages of
+// enabled sources are collected.
 func (t *TGAdapter) setupHandlers() {
 	// Channels and Supergroups
 	t.dispatcher.OnNewChannelMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewChannelMessage) error {
-		msg, ok := update.Message.(*tg.Message)
-		if !ok || msg.Out {
+		msg, ok := channelMessage(update.Message, e)
+		if !ok || !t.admit(ctx, &msg) {
 			return nil
 		}
+		t.attach(ctx, t.client.API(), &msg, false)
+		return t.handle(ctx, msg)
+	})
 
-		peer, ok := msg.PeerID.(*tg.PeerChannel)
-		if !ok {
+	// Legacy Groups and Private Chats
+	t.dispatcher.OnNewMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewMessage) error {
+		msg, ok := t.chatMessage(update.Message, e)
+		if !ok || !t.admit(ctx, &msg) {
 			return nil
 		}
+		t.attach(ctx, t.client.API(), &msg, false)
+		return t.handle(ctx, msg)
+	})
 
-		return t.service.Handle(ctx, core.Message{
-			Text:      msg.Message,
-			ChannelID: peer.ChannelID,
-			MessageID: msg.ID,
-			RawData:   msg,
-		})
+	// Edits, e.g. a salary added or the vacancy marked as closed
+	t.dispatcher.OnEditChannelMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateEditChannelMessage) error {
+		msg, ok := channelMessage(update.Message, e)
+		if !ok || !t.admit(ctx, &msg) {
+			return nil
+		}
+		return t.handleEdit(ctx, msg, e)
 	})
 
-	// Legacy Groups and Private Chats
-	t.dispatcher.OnNewMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewMessage) error {
-		msg, ok := update.Message.(*tg.Message)
-		if !ok || msg.Out {
+	t.dispatcher.OnEditMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateEditMessage) error {
+		msg, ok := t.chatMessage(update.Message, e)
+		if !ok || !t.admit(ctx, &msg) {
 			return nil
 		}
+		return t.handleEdit(ctx, msg, e)
+	})
+
+	// Deleted posts withdraw their jobs. Deletions in legacy groups and
+	// private chats don't name the chat, so only channels are followed.
+	t.dispatcher.OnDeleteChannelMessages(func(ctx context.Context, e tg.Entities, update *tg.UpdateDeleteChannelMessages) error {
+		albums := t.service.Albums(ctx, update.ChannelID, update.Messages)
+		if err := t.service.HandleDelete(ctx, update.ChannelID, update.Messages); err != nil {
+			return err
+		}
 
-		var peerID int64
-		switch p := msg.PeerID.(type) {
-		case *tg.PeerChat:
-			peerID = p.ChatID
-		case *tg.PeerUser:
-			peerID = p.UserID
-			if user, ok := e.Users[p.UserID]; ok {
-				if !user.Bot {
-					t.logger.Debug("Ignoring private message from human user", zap.Int64("user_id", p.UserID))
-					return nil
-				}
+		// Albums losing some of their messages are collected from the rest
+		for _, album := range albums {
+			remaining := slices.DeleteFunc(album, func(id int) bool { return slices.Contains(update.Messages, id) })
+			if len(remaining) > 0 {
+				t.shrinkAlbum(ctx, update.ChannelID, remaining[0])
 			}
-		default:
-			return nil
 		}
+		return nil
+	})
+}
 
-		return t.service.Handle(ctx, core.Message{
-			Text:      msg.Message,
-			ChannelID: peerID,
-			MessageID: msg.ID,
-			RawData:   msg,
-		})
+// handle collects a new message. Parts of an album are buffered and
+// collected as one message.
+func (t *TGAdapter) handle(ctx context.Context, msg core.Message) error {
+	if msg.AlbumID == 0 {
+		return t.service.Handle(ctx, msg)
+	}
+
+	// The update state moves past the part once this returns; it is kept
+	// until the album is collected, see recoverAlbums
+	err := t.pending.Add(ctx, msg.ChannelID, msg.AlbumID, msg.MessageID)
+	if err != nil {
+		err = fmt.Errorf("failed to record album part: %w", err)
+	}
+	t.albums.add(ctx, msg)
+	return err
+}
+
+// recoverAlbums collects the albums left pending by the previous run, e.g.
+// when it crashed before their parts were handed over. Albums that can't be
+// fetched stay pending for the next start.
+func (t *TGAdapter) recoverAlbums(ctx context.Context, api *tg.Client) {
+	albums, err := t.pending.List(ctx)
+	if err != nil {
+		t.logger.Error("Failed to list pending albums", zap.Error(err))
+		return
+	}
+
+	for _, album := range albums {
+		if len(album.MessageIDs) == 0 {
+			continue
+		}
+
+		source, err := t.sources.Find(ctx, core.SourceRef{PeerID: album.PeerID})
+		if err != nil {
+			t.logger.Warn("Source of pending album not found", zap.Int64("channelId", album.PeerID), zap.Error(err))
+			continue
+		}
+		parts, err := t.albumParts(ctx, api, source, tg.Entities{}, album.MessageIDs[0], album.AlbumID)
+		if err != nil {
+			t.logger.Warn("Pending album not recovered",
+				zap.Int64("channelId", album.PeerID),
+				zap.Ints("msgIds", album.MessageIDs),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		if err := t.service.Handle(ctx, t.attachAlbum(ctx, api, parts, false)); err != nil {
+			t.logger.Error("Failed to handle album", zap.Int64("channelId", album.PeerID), zap.Error(err))
+			continue
+		}
+		if err := t.pending.Remove(ctx, album.PeerID, album.AlbumID); err != nil {
+			t.logger.Warn("Failed to remove pending album", zap.Int64("channelId", album.PeerID), zap.Error(err))
+		}
+	}
+}
+
+// handleEdit collects an edited message. An edited part of an album is
+// collected with the rest of the album, as the album's job.
+func (t *TGAdapter) handleEdit(ctx context.Context, msg core.Message, e tg.Entities) error {
+	api := t.client.API()
+	if msg.AlbumID == 0 {
+		t.attach(ctx, api, &msg, true)
+		return t.service.HandleEdit(ctx, msg)
+	}
+
+	parts, err := t.albumParts(ctx, api, *msg.Source, e, msg.MessageID, msg.AlbumID)
+	if err != nil {
+		t.logger.Warn("Edited album not collected",
+			zap.Int64("channelId", msg.ChannelID),
+			zap.Int("msgId", msg.MessageID),
+			zap.Error(err),
+		)
+		return nil
+	}
+	return t.service.HandleEdit(ctx, t.attachAlbum(ctx, api, parts, true))
+}
+
+// shrinkAlbum collects an album again from the messages left after some
+// were deleted.
+func (t *TGAdapter) shrinkAlbum(ctx context.Context, channelID int64, messageID int) {
+	source, err := t.sources.Find(ctx, core.SourceRef{PeerID: channelID})
+	if err != nil {
+		t.logger.Warn("Source of album not found", zap.Int64("channelId", channelID), zap.Error(err))
+		return
+	}
+
+	api := t.client.API()
+	parts, err := t.albumParts(ctx, api, source, tg.Entities{}, messageID, 0)
+	if err != nil {
+		t.logger.Warn("Album left after deletion not collected",
+			zap.Int64("channelId", channelID),
+			zap.Int("msgId", messageID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	if err := t.service.HandleEdit(ctx, t.attachAlbum(ctx, api, parts, true)); err != nil {
+		t.logger.Warn("Album left after deletion not collected",
+			zap.Int64("channelId", channelID),
+			zap.Int("msgId", messageID),
+			zap.Error(err),
+		)
+	}
+}
+
+// albumParts fetches the messages of the album a message is part of, of the
+// given album if albumID isn't 0. Albums hold up to maxAlbumSize messages
+// posted together, so the messages around it are enough.
+func (t *TGAdapter) albumParts(ctx context.Context, api *tg.Client, source core.Source, e tg.Entities, messageID int, albumID int64) ([]core.Message, error) {
+	peer, err := t.inputPeer(ctx, source, e)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := api.MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
+		Peer:     peer,
+		OffsetID: messageID + maxAlbumSize,
+		Limit:    2 * maxAlbumSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album: %w", err)
+	}
+	page, ok := res.AsModified()
+	if !ok {
+		return nil, fmt.Errorf("failed to get album: history not modified")
+	}
+
+	messages := make(map[int]*tg.Message)
+	for _, m := range page.GetMessages() {
+		if msg, ok := m.(*tg.Message); ok {
+			messages[msg.ID] = msg
+		}
+	}
+	if albumID == 0 {
+		if msg, ok := messages[messageID]; ok {
+			albumID, _ = msg.GetGroupedID()
+		}
+		if albumID == 0 {
+			return nil, fmt.Errorf("message %d is not part of an album", messageID)
+		}
+	}
+
+	entities := tg.Entities{Users: tg.UserClassArray(page.GetUsers()).UserToMap()}
+	var parts []core.Message
+	for _, msg := range messages {
+		if groupedID, ok := msg.GetGroupedID(); !ok || groupedID != albumID {
+			continue
+		}
+		part := toMessage(msg, source.PeerID, entities)
+		part.Source = &source
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("album %d not found", albumID)
+	}
+	return parts, nil
+}
+
+// inputPeer returns the peer to request messages of a source from, taken
+// from the entities of an update or the access hashes saved with the
+// update state.
+func (t *TGAdapter) inputPeer(ctx context.Context, source core.Source, e tg.Entities) (tg.InputPeerClass, error) {
+	switch source.Type {
+	case core.SourceGroup:
+		return &tg.InputPeerChat{ChatID: source.PeerID}, nil
+	case core.SourceBot:
+		if user, ok := e.Users[source.PeerID]; ok {
+			return user.AsInputPeer(), nil
+		}
+		return nil, fmt.Errorf("access hash of bot %d unknown", source.PeerID)
+	}
+
+	if channel, ok := e.Channels[source.PeerID]; ok {
+		return channel.AsInputPeer(), nil
+	}
+	accessHash, found, err := t.state.GetChannelAccessHash(ctx, t.selfID.Load(), source.PeerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access hash: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("access hash of channel %d unknown", source.PeerID)
+	}
+	return &tg.InputPeerChannel{ChannelID: source.PeerID, AccessHash: accessHash}, nil
+}
+
+// admit looks up the source of a message, registering chats seen for the
+// first time pending review. It returns false if the source is disabled.
+func (t *TGAdapter) admit(ctx context.Context, msg *core.Message) bool {
+	source, err := t.sources.Resolve(ctx, *msg.Source)
+	if err != nil {
+		t.logger.Error("Failed to resolve message source",
+			zap.Int64("peerId", msg.ChannelID),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	// Updates don't always carry the channel; look it up once, so that its
+	// posts link by username
+	if source.Type == "" {
+		if channel, ok := t.describeChannel(ctx, source.PeerID); ok {
+			if source, err = t.sources.Resolve(ctx, *channelSource(channel)); err != nil {
+				t.logger.Error("Failed to resolve message source",
+					zap.Int64("peerId", msg.ChannelID),
+					zap.Error(err),
+				)
+				return false
+			}
+		}
+	}
+
+	if !source.Enabled {
+		t.logger.Debug("Ignoring message from disabled source",
+			zap.Int64("peerId", source.PeerID),
+			zap.String("source", source.Label()),
+			zap.Bool("pending", source.Pending),
+		)
+		return false
+	}
+
+	msg.Source = &source
+	return true
+}
+
+// attach downloads the document attached to a message if its text can be
+// read, e.g. a vacancy posted as a PDF. Photos are collected by caption only.
+// An edit keeping the document its job holds refers to it without a
+// download.
+func (t *TGAdapter) attach(ctx context.Context, api *tg.Client, msg *core.Message, edited bool) {
+	doc, attachment, ok := t.document(*msg)
+	if !ok {
+		return
+	}
+	if edited && t.service.HasDocuments(ctx, msg.ChannelID, msg.MessageID, []string{attachment.ID}) {
+		msg.Attachments = append(msg.Attachments, attachment)
+		return
+	}
+	if t.download(ctx, api, *msg, doc, &attachment) {
+		msg.Attachments = append(msg.Attachments, attachment)
+	}
+}
+
+// attachAlbum merges the parts of an album with their documents. An edit
+// keeping the documents the album's job holds refers to them without a
+// download.
+func (t *TGAdapter) attachAlbum(ctx context.Context, api *tg.Client, parts []core.Message, edited bool) core.Message {
+	if edited {
+		kept := slices.Clone(parts)
+		for i := range kept {
+			if _, attachment, ok := t.document(kept[i]); ok {
+				kept[i].Attachments = append(kept[i].Attachments, attachment)
+			}
+		}
+
+		merged := core.MergeAlbum(kept)
+		ids := make([]string, 0, len(merged.Attachments))
+		for _, attachment := range merged.Attachments {
+			ids = append(ids, attachment.ID)
+		}
+		if t.service.HasDocuments(ctx, merged.ChannelID, merged.MessageID, ids) {
+			return merged
+		}
+	}
+
+	parts = slices.Clone(parts)
+	for i := range parts {
+		t.attach(ctx, api, &parts[i], false)
+	}
+	return core.MergeAlbum(parts)
+}
+
+// document returns the document attached to a message if its text can be
+// read and it isn't too large, with its attachment lacking the data.
+func (t *TGAdapter) document(msg core.Message) (*tg.Document, core.Attachment, bool) {
+	raw, ok := msg.RawData.(*tg.Message)
+	if !ok || t.cfg.AttachmentMaxSize <= 0 {
+		return nil, core.Attachment{}, false
+	}
+	media, ok := raw.Media.(*tg.MessageMediaDocument)
+	if !ok {
+		return nil, core.Attachment{}, false
+	}
+	doc, ok := media.Document.(*tg.Document)
+	if !ok {
+		return nil, core.Attachment{}, false
+	}
+
+	var name string
+	for _, attribute := range doc.Attributes {
+		if filename, ok := attribute.(*tg.DocumentAttributeFilename); ok {
+			name = filename.FileName
+		}
+	}
+	if _, ok := core.DocumentType(doc.MimeType, name); !ok {
+		return nil, core.Attachment{}, false
+	}
+
+	if doc.Size > int64(t.cfg.AttachmentMaxSize) {
+		t.logger.Debug("Attached document too large, collecting caption only",
+			zap.Int64("channelId", msg.ChannelID),
+			zap.Int("msgId", msg.MessageID),
+			zap.Int64("size", doc.Size),
+		)
+		return nil, core.Attachment{}, false
+	}
+
+	return doc, core.Attachment{ID: strconv.FormatInt(doc.ID, 10), Name: name, MimeType: doc.MimeType}, true
+}
+
+// download fetches the data of an attached document. It returns false if
+// the download failed.
+func (t *TGAdapter) download(ctx context.Context, api *tg.Client, msg core.Message, doc *tg.Document, attachment *core.Attachment) bool {
+	var data bytes.Buffer
+	if _, err := downloader.NewDownloader().Download(api, doc.AsInputDocumentFileLocation()).Stream(ctx, &data); err != nil {
+		t.logger.Warn("Failed to download attached document",
+			zap.Int64("channelId", msg.ChannelID),
+			zap.Int("msgId", msg.MessageID),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	attachment.Data = data.Bytes()
+	return true
+}
+
+// describeChannel fetches a channel by the access hash saved with the update
+// state. It returns false if the channel is unknown or can't be fetched.
+func (t *TGAdapter) describeChannel(ctx context.Context, channelID int64) (*tg.Channel, bool) {
+	if !t.running.Load() {
+		return nil, false
+	}
+
+	accessHash, found, err := t.state.GetChannelAccessHash(ctx, t.selfID.Load(), channelID)
+	if err != nil || !found {
+		return nil, false
+	}
+
+	chats, err := t.client.API().ChannelsGetChannels(ctx, []tg.InputChannelClass{
+		&tg.InputChannel{ChannelID: channelID, AccessHash: accessHash},
 	})
+	if err != nil {
+		t.logger.Warn("Failed to get channel", zap.Int64("channel_id", channelID), zap.Error(err))
+		return nil, false
+	}
+
+	channel, ok := tg.ChatClassArray(chats.GetChats()).ChannelToMap()[channelID]
+	return channel, ok
 }
 
-// RegisterCommand adds tg-login command to PocketBase.
+// channelMessage converts a message of a channel or supergroup.
+func channelMessage(m tg.MessageClass, e tg.Entities) (core.Message, bool) {
+	msg, ok := m.(*tg.Message)
+	if !ok || msg.Out {
+		return core.Message{}, false
+	}
+
+	peer, ok := msg.PeerID.(*tg.PeerChannel)
+	if !ok {
+		return core.Message{}, false
+	}
+
+	message := toMessage(msg, peer.ChannelID, e)
+	message.Source = &core.Source{PeerID: peer.ChannelID}
+	if channel, ok := e.Channels[peer.ChannelID]; ok {
+		message.Source = channelSource(channel)
+	}
+	return message, true
+}
+
+// channelSource describes a channel or supergroup as a source.
+func channelSource(channel *tg.Channel) *core.Source {
+	source := &core.Source{
+		PeerID:   channel.ID,
+		Type:     core.SourceChannel,
+		Title:    channel.Title,
+		Username: strings.ToLower(channel.Username),
+	}
+	if channel.Megagroup {
+		source.Type = core.SourceSupergroup
+	}
+	return source
+}
+
+// chatMessage converts a message of a legacy group or a bot chat. Private
+// messages from people are ignored.
+func (t *TGAdapter) chatMessage(m tg.MessageClass, e tg.Entities) (core.Message, bool) {
+	msg, ok := m.(*tg.Message)
+	if !ok || msg.Out {
+		return core.Message{}, false
+	}
+
+	var source *core.Source
+	switch p := msg.PeerID.(type) {
+	case *tg.PeerChat:
+		source = &core.Source{PeerID: p.ChatID, Type: core.SourceGroup}
+		if chat, ok := e.Chats[p.ChatID]; ok {
+			source.Title = chat.Title
+		}
+	case *tg.PeerUser:
+		source = &core.Source{PeerID: p.UserID, Type: core.SourceBot}
+		if user, ok := e.Users[p.UserID]; ok {
+			if !user.Bot {
+				t.logger.Debug("Ignoring private message from human user", zap.Int64("user_id", p.UserID))
+				return core.Message{}, false
+			}
+			source.Title = user.FirstName
+			source.Username = strings.ToLower(user.Username)
+		}
+	default:
+		return core.Message{}, false
+	}
+
+	message := toMessage(msg, source.PeerID, e)
+	message.Source = source
+	return message, true
+}
+
+// toMessage converts a Telegram message of a chat into a collector message.
+func toMessage(msg *tg.Message, chatID int64, e tg.Entities) core.Message {
+	message := core.Message{
+		Text:      msg.Message,
+		ChannelID: chatID,
+		MessageID: msg.ID,
+		Date:      time.Unix(int64(msg.Date), 0),
+		Entities:  messageEntities(msg, e),
+		RawData:   msg,
+	}
+	if editDate, ok := msg.GetEditDate(); ok {
+		message.EditDate = time.Unix(int64(editDate), 0)
+	}
+	if albumID, ok := msg.GetGroupedID(); ok {
+		message.AlbumID = albumID
+	}
+	return message
+}
+
+// messageEntities extracts formatting, mentions, links, emails and phones
+// from a message. Entity offsets are in UTF-16 code units.
+func messageEntities(msg *tg.Message, e tg.Entities) []core.Entity {
+	text := utf16.Encode([]rune(msg.Message))
+	slice := func(offset, length int) string {
+		if offset < 0 || length <= 0 || offset+length > len(text) {
+			return ""
+		}
+		return string(utf16.Decode(text[offset : offset+length]))
+	}
+
+	var entities []core.Entity
+	for _, entity := range msg.Entities {
+		kind := entityKind(entity)
+		if kind == "" {
+			continue
+		}
+
+		item := core.Entity{
+			Kind:   kind,
+			Text:   slice(entity.GetOffset(), entity.GetLength()),
+			Offset: entity.GetOffset(),
+			Length: entity.GetLength(),
+		}
+		switch v := entity.(type) {
+		case *tg.MessageEntityMentionName:
+			// Mentions of users without a username can't be linked by name
+			user, ok := e.Users[v.UserID]
+			if !ok || user.Username == "" {
+				continue
+			}
+			item.Text = "@" + user.Username
+		case *tg.MessageEntityTextURL:
+			item.URL = v.URL
+		}
+		entities = append(entities, item)
+	}
+
+	return entities
+}
+
+// entityKind maps a Telegram entity to its kind, empty for entities that
+// are neither contacts nor formatting, e.g. hashtags and custom emoji.
+func entityKind(entity tg.MessageEntityClass) core.EntityKind {
+	switch entity.(type) {
+	case *tg.MessageEntityMention, *tg.MessageEntityMentionName:
+		return core.EntityMention
+	case *tg.MessageEntityURL:
+		return core.EntityURL
+	case *tg.MessageEntityTextURL:
+		return core.EntityTextURL
+	case *tg.MessageEntityEmail:
+		return core.EntityEmail
+	case *tg.MessageEntityPhone:
+		return core.EntityPhone
+	case *tg.MessageEntityBold:
+		return core.EntityBold
+	case *tg.MessageEntityItalic:
+		return core.EntityItalic
+	case *tg.MessageEntityUnderline:
+		return core.EntityUnderline
+	case *tg.MessageEntityStrike:
+		return core.EntityStrike
+	case *tg.MessageEntitySpoiler:
+		return core.EntitySpoiler
+	case *tg.MessageEntityCode:
+		return core.EntityCode
+	case *tg.MessageEntityPre:
+		return core.EntityPre
+	case *tg.MessageEntityBlockquote:
+		return core.EntityBlockquote
+	}
+	return ""
+}
+
+// RegisterCommand adds tg-login, tg-backfill and tg-sources commands to PocketBase.
 func (t *TGAdapter) RegisterCommand(app *pocketbase.PocketBase) {
 	app.RootCmd.AddCommand(&cobra.Command{
 		Use:   "tg-login",
@@ -129,6 +715,9 @@ func (t *TGAdapter) RegisterCommand(app *pocketbase.PocketBase) {
 			}
 		},
 	})
+
+	app.RootCmd.AddCommand(t.backfillCommand())
+	app.RootCmd.AddCommand(t.sourcesCommand())
 }
 
 // Login performs interactive Telegram authentication.
@@ -158,8 +747,15 @@ func (t *TGAdapter) Login(ctx context.Context) error {
 	})
 }
 
-// Start begins listening for Telegram messages.
+// Start begins listening for Telegram messages. Messages posted since the
+// last run are caught up on first, from the persisted update state.
 func (t *TGAdapter) Start(ctx context.Context) error {
+	unlock, err := lockSession(t.cfg.SessionPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	return t.client.Run(ctx, func(ctx context.Context) error {
 		status, err := t.client.Auth().Status(ctx)
 		if err != nil {
@@ -180,8 +776,20 @@ func (t *TGAdapter) Start(ctx context.Context) error {
 			zap.Int64("user_id", self.ID),
 		)
 
-		<-ctx.Done()
-		return ctx.Err()
+		t.selfID.Store(self.ID)
+		t.running.Store(true)
+		defer t.running.Store(false)
+
+		// Albums still waiting for parts are collected as they are
+		defer t.albums.flushAll()
+
+		t.recoverAlbums(ctx, t.client.API())
+
+		return t.gaps.Run(ctx, t.client.API(), self.ID, updates.AuthOptions{
+			OnStart: func(ctx context.Context) {
+				t.logger.Info("Catching up on missed updates")
+			},
+		})
 	})
 }
 
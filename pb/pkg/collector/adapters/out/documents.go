@@ -0,0 +1,158 @@
+package out
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+
+	"svpb-tmpl/pkg/collector/core"
+)
+
+// blankLines matches runs of empty lines left by page and paragraph breaks.
+var blankLines = regexp.MustCompile(`\n\s*\n\s*\n+`)
+
+const (
+	// maxTextBytes is how much text is read from a document, more than a
+	// job's text holds. Reading stops there, so a small compressed file
+	// can't expand into unbounded memory.
+	maxTextBytes = 32 << 10
+	// maxDocumentXML caps the decompressed word/document.xml read; markup
+	// makes up most of it.
+	maxDocumentXML = 8 << 20
+)
+
+// Documents implements core.DocumentReader for PDF, DOCX and plain text
+// documents. Text is read locally, nothing is sent anywhere.
+type Documents struct{}
+
+// NewDocuments creates a new document reader.
+func NewDocuments() *Documents {
+	return &Documents{}
+}
+
+// Read returns the plain text of a document.
+func (d *Documents) Read(ctx context.Context, doc core.Attachment) (string, error) {
+	docType, ok := core.DocumentType(doc.MimeType, doc.Name)
+	if !ok {
+		return "", core.ErrUnsupportedDocument
+	}
+
+	var (
+		text string
+		err  error
+	)
+	switch docType {
+	case core.DocumentPDF:
+		text, err = readPDF(doc.Data)
+	case core.DocumentDOCX:
+		text, err = readDOCX(doc.Data)
+	case core.DocumentTXT:
+		data := doc.Data[:min(len(doc.Data), maxTextBytes)]
+		text = strings.ToValidUTF8(string(data), "")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", doc.Name, err)
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.TrimSpace(blankLines.ReplaceAllString(text, "\n\n")), nil
+}
+
+// readPDF extracts the text layer of a PDF. Scanned PDFs have none.
+func readPDF(data []byte) (text string, err error) {
+	// The parser panics on some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed pdf: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i := 1; i <= reader.NumPage() && b.Len() < maxTextBytes; i++ {
+		page, err := reader.Page(i).GetPlainText(nil)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(page)
+	}
+	return truncateBytes(b.String(), maxTextBytes), nil
+}
+
+// readDOCX extracts the paragraphs of word/document.xml.
+func readDOCX(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	file, err := archive.Open("word/document.xml")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var (
+		b      strings.Builder
+		inText bool
+	)
+	limited := &io.LimitedReader{R: file, N: maxDocumentXML}
+	decoder := xml.NewDecoder(limited)
+	for b.Len() < maxTextBytes {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Cut off at the limit, keep the text read so far
+			if limited.N <= 0 {
+				break
+			}
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteString("\t")
+			case "br", "cr":
+				b.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+
+	return truncateBytes(b.String(), maxTextBytes), nil
+}
+
+// truncateBytes cuts text to at most n bytes without splitting a character.
+func truncateBytes(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	return strings.ToValidUTF8(text[:n], "")
+}
@@ -0,0 +1,277 @@
+package out
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/gotd/td/telegram/updates"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	pbcore "github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	tgStatesCollection        = "tgStates"
+	tgChannelStatesCollection = "tgChannelStates"
+)
+
+var (
+	_ updates.StateStorage        = (*TGState)(nil)
+	_ updates.ChannelAccessHasher = (*TGState)(nil)
+)
+
+// TGState implements gotd's updates.StateStorage and ChannelAccessHasher on
+// the tgStates and tgChannelStates collections, so the collector resumes
+// from where it stopped after a restart instead of from the server's
+// current state.
+type TGState struct {
+	app *pocketbase.PocketBase
+
+	// mu serializes read-modify-write of records; channels are updated
+	// from their own goroutines
+	mu sync.Mutex
+}
+
+// NewTGState creates a new update state storage.
+func NewTGState(app *pocketbase.PocketBase) *TGState {
+	return &TGState{app: app}
+}
+
+// GetState returns the common state of a user.
+func (s *TGState) GetState(ctx context.Context, userID int64) (updates.State, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.findState(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return updates.State{}, false, nil
+	}
+	if err != nil {
+		return updates.State{}, false, fmt.Errorf("failed to find state: %w", err)
+	}
+
+	return updates.State{
+		Pts:  record.GetInt("pts"),
+		Qts:  record.GetInt("qts"),
+		Date: record.GetInt("date"),
+		Seq:  record.GetInt("seq"),
+	}, true, nil
+}
+
+// SetState replaces the common state of a user. Channel pts are relative to
+// the previous state and are forgotten; access hashes are kept.
+func (s *TGState) SetState(ctx context.Context, userID int64, state updates.State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.findState(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		collection, err := s.app.FindCollectionByNameOrId(tgStatesCollection)
+		if err != nil {
+			return fmt.Errorf("%s collection not found: %w", tgStatesCollection, err)
+		}
+		record = pbcore.NewRecord(collection)
+		record.Set("userId", formatID(userID))
+	} else if err != nil {
+		return fmt.Errorf("failed to find state: %w", err)
+	}
+
+	record.Set("pts", state.Pts)
+	record.Set("qts", state.Qts)
+	record.Set("date", state.Date)
+	record.Set("seq", state.Seq)
+
+	return s.app.RunInTransaction(func(txApp pbcore.App) error {
+		if err := txApp.Save(record); err != nil {
+			return fmt.Errorf("failed to save state: %w", err)
+		}
+
+		_, err := txApp.DB().
+			Update(tgChannelStatesCollection, dbx.Params{"pts": 0}, dbx.HashExp{"userId": formatID(userID)}).
+			Execute()
+		if err != nil {
+			return fmt.Errorf("failed to reset channel states: %w", err)
+		}
+		return nil
+	})
+}
+
+// SetPts updates the common pts of a user.
+func (s *TGState) SetPts(ctx context.Context, userID int64, pts int) error {
+	return s.updateState(userID, map[string]any{"pts": pts})
+}
+
+// SetQts updates the common qts of a user.
+func (s *TGState) SetQts(ctx context.Context, userID int64, qts int) error {
+	return s.updateState(userID, map[string]any{"qts": qts})
+}
+
+// SetDate updates the common state date of a user.
+func (s *TGState) SetDate(ctx context.Context, userID int64, date int) error {
+	return s.updateState(userID, map[string]any{"date": date})
+}
+
+// SetSeq updates the common seq of a user.
+func (s *TGState) SetSeq(ctx context.Context, userID int64, seq int) error {
+	return s.updateState(userID, map[string]any{"seq": seq})
+}
+
+// SetDateSeq updates the common state date and seq of a user.
+func (s *TGState) SetDateSeq(ctx context.Context, userID int64, date, seq int) error {
+	return s.updateState(userID, map[string]any{"date": date, "seq": seq})
+}
+
+// GetChannelPts returns the pts of a channel.
+func (s *TGState) GetChannelPts(ctx context.Context, userID, channelID int64) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.findChannel(userID, channelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to find channel state: %w", err)
+	}
+
+	pts := record.GetInt("pts")
+	return pts, pts > 0, nil
+}
+
+// SetChannelPts updates the pts of a channel.
+func (s *TGState) SetChannelPts(ctx context.Context, userID, channelID int64, pts int) error {
+	return s.updateChannel(userID, channelID, "pts", pts)
+}
+
+// ForEachChannels calls f for every channel with a known pts.
+func (s *TGState) ForEachChannels(ctx context.Context, userID int64, f func(ctx context.Context, channelID int64, pts int) error) error {
+	s.mu.Lock()
+	records, err := s.app.FindRecordsByFilter(
+		tgChannelStatesCollection,
+		"userId = {:userId} && pts > 0",
+		"",
+		0,
+		0,
+		dbx.Params{"userId": formatID(userID)},
+	)
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to list channel states: %w", err)
+	}
+
+	for _, record := range records {
+		channelID, err := strconv.ParseInt(record.GetString("channelId"), 10, 64)
+		if err != nil {
+			continue
+		}
+		if err := f(ctx, channelID, record.GetInt("pts")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetChannelAccessHash stores the access hash of a channel.
+func (s *TGState) SetChannelAccessHash(ctx context.Context, userID, channelID, accessHash int64) error {
+	return s.updateChannel(userID, channelID, "accessHash", formatID(accessHash))
+}
+
+// GetChannelAccessHash returns the stored access hash of a channel.
+func (s *TGState) GetChannelAccessHash(ctx context.Context, userID, channelID int64) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.findChannel(userID, channelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to find channel state: %w", err)
+	}
+
+	raw := record.GetString("accessHash")
+	if raw == "" {
+		return 0, false, nil
+	}
+	accessHash, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid access hash of channel %d: %w", channelID, err)
+	}
+	return accessHash, true, nil
+}
+
+// updateState sets fields of an existing common state. gotd expects an
+// error if there is none yet.
+func (s *TGState) updateState(userID int64, fields map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.findState(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("state of user %d not found", userID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find state: %w", err)
+	}
+
+	for field, value := range fields {
+		record.Set(field, value)
+	}
+
+	if err := s.app.Save(record); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// updateChannel sets a field of a channel state, creating it if needed.
+func (s *TGState) updateChannel(userID, channelID int64, field string, value any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.findChannel(userID, channelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		collection, err := s.app.FindCollectionByNameOrId(tgChannelStatesCollection)
+		if err != nil {
+			return fmt.Errorf("%s collection not found: %w", tgChannelStatesCollection, err)
+		}
+		record = pbcore.NewRecord(collection)
+		record.Set("userId", formatID(userID))
+		record.Set("channelId", formatID(channelID))
+	} else if err != nil {
+		return fmt.Errorf("failed to find channel state: %w", err)
+	}
+
+	record.Set(field, value)
+
+	if err := s.app.Save(record); err != nil {
+		return fmt.Errorf("failed to save channel state: %w", err)
+	}
+	return nil
+}
+
+func (s *TGState) findState(userID int64) (*pbcore.Record, error) {
+	return s.app.FindFirstRecordByFilter(
+		tgStatesCollection,
+		"userId = {:userId}",
+		dbx.Params{"userId": formatID(userID)},
+	)
+}
+
+func (s *TGState) findChannel(userID, channelID int64) (*pbcore.Record, error) {
+	return s.app.FindFirstRecordByFilter(
+		tgChannelStatesCollection,
+		"userId = {:userId} && channelId = {:channelId}",
+		dbx.Params{"userId": formatID(userID), "channelId": formatID(channelID)},
+	)
+}
+
+// formatID formats Telegram ids, which may exceed float precision of
+// number fields, for text fields.
+func formatID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
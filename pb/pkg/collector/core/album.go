@@ -0,0 +1,91 @@
+package core
+
+import (
+	"slices"
+	"unicode/utf16"
+)
+
+// PendingAlbum is an album whose parts were received but not collected yet.
+type PendingAlbum struct {
+	PeerID     int64
+	AlbumID    int64
+	MessageIDs []int
+}
+
+// MergeAlbum combines the messages of an album into one. Telegram sends
+// every photo or document of an album as a message of its own, with the
+// caption on one of them. The merged message takes its id from the first
+// part with a caption and lists the ids of all parts, so that edits and
+// deletes of any part find its job.
+func MergeAlbum(parts []Message) Message {
+	parts = slices.Clone(parts)
+	slices.SortFunc(parts, func(a, b Message) int { return a.MessageID - b.MessageID })
+
+	primary := parts[0]
+	for _, part := range parts {
+		if part.Text != "" {
+			primary = part
+			break
+		}
+	}
+
+	merged := primary
+	merged.Text = ""
+	merged.Entities = nil
+	merged.Attachments = nil
+	merged.AlbumMessageIDs = nil
+
+	offset := 0
+	for _, part := range parts {
+		merged.AlbumMessageIDs = append(merged.AlbumMessageIDs, part.MessageID)
+		if part.Date.Before(merged.Date) {
+			merged.Date = part.Date
+		}
+		if part.EditDate.After(merged.EditDate) {
+			merged.EditDate = part.EditDate
+		}
+		merged.Attachments = append(merged.Attachments, part.Attachments...)
+
+		if part.Text == "" {
+			continue
+		}
+		if merged.Text != "" {
+			merged.Text += "\n\n"
+			offset += 2
+		}
+		merged.Text += part.Text
+
+		// Entity offsets move by the captions before, in UTF-16 code units
+		for _, entity := range part.Entities {
+			entity.Offset += offset
+			merged.Entities = append(merged.Entities, entity)
+		}
+		offset += len(utf16.Encode([]rune(part.Text)))
+	}
+
+	return merged
+}
+
+// MergeAlbums merges the parts of albums in a list of messages, e.g. a page
+// of channel history. Merged albums take the place of their first part.
+func MergeAlbums(messages []Message) []Message {
+	parts := make(map[int64][]Message)
+	for _, msg := range messages {
+		if msg.AlbumID != 0 {
+			parts[msg.AlbumID] = append(parts[msg.AlbumID], msg)
+		}
+	}
+
+	merged := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.AlbumID == 0 {
+			merged = append(merged, msg)
+			continue
+		}
+		if album, ok := parts[msg.AlbumID]; ok {
+			merged = append(merged, MergeAlbum(album))
+			delete(parts, msg.AlbumID)
+		}
+	}
+	return merged
+}
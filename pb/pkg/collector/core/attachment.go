@@ -0,0 +1,49 @@
+package core
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsupportedDocument is returned for documents whose text can't be read.
+var ErrUnsupportedDocument = errors.New("unsupported document type")
+
+// Document types whose text is read, by MIME type.
+const (
+	DocumentPDF  = "application/pdf"
+	DocumentDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	DocumentTXT  = "text/plain"
+)
+
+// documentExtensions map file extensions to document types, for documents
+// sent with a generic MIME type.
+var documentExtensions = map[string]string{
+	".pdf":  DocumentPDF,
+	".docx": DocumentDOCX,
+	".txt":  DocumentTXT,
+}
+
+// Attachment is a document attached to a message, e.g. a vacancy as a PDF
+// posted with a short caption.
+type Attachment struct {
+	// ID identifies the document in Telegram
+	ID       string
+	Name     string
+	MimeType string
+	// Data is nil for a document the message's job already holds
+	Data []byte
+}
+
+// DocumentType returns the type of a document to read its text as, false
+// if documents of its MIME type and name aren't read.
+func DocumentType(mimeType, name string) (string, bool) {
+	mimeType, _, _ = strings.Cut(strings.ToLower(mimeType), ";")
+	switch mimeType = strings.TrimSpace(mimeType); mimeType {
+	case DocumentPDF, DocumentDOCX, DocumentTXT:
+		return mimeType, true
+	}
+
+	docType, ok := documentExtensions[strings.ToLower(filepath.Ext(name))]
+	return docType, ok
+}
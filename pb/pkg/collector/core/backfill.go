@@ -0,0 +1,66 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrBackfillRunning is returned when a backfill is started while another
+// one is in progress.
+var ErrBackfillRunning = errors.New("backfill already running")
+
+// ErrSessionInUse is returned when another process, e.g. the server running
+// the collector, holds the Telegram session.
+var ErrSessionInUse = errors.New("telegram session in use by another process - start backfills with POST /api/collector/backfill while the server is running")
+
+// BackfillRequest selects the history of a channel to collect.
+type BackfillRequest struct {
+	// Channel is a public username, with or without @ or a t.me/ prefix
+	Channel string `json:"channel"`
+	// Until stops at messages posted before it, zero for no limit
+	Until time.Time `json:"until"`
+	// UntilID stops at messages with this id or lower, 0 for no limit
+	UntilID int `json:"untilId"`
+	// Restart ignores the saved cursor and starts from the newest message
+	Restart bool `json:"restart"`
+}
+
+// BackfillCursor is the saved progress of a channel backfill. History is
+// read newest first, so everything above OffsetID has been handled.
+type BackfillCursor struct {
+	Channel   string    `json:"channel"`
+	ChannelID int64     `json:"channelId"`
+	OffsetID  int       `json:"offsetId"`
+	Until     time.Time `json:"until"`
+	UntilID   int       `json:"untilId"`
+	// Messages is the number of messages handled so far
+	Messages  int       `json:"messages"`
+	Done      bool      `json:"done"`
+	LastError string    `json:"lastError"`
+	Updated   time.Time `json:"updated"`
+}
+
+// Extend applies the bounds of a request to a saved cursor. A finished
+// backfill is resumed when the request reaches further back.
+func (c *BackfillCursor) Extend(req BackfillRequest) {
+	if !req.Until.IsZero() && (c.Until.IsZero() || req.Until.Before(c.Until)) {
+		c.Until = req.Until
+		c.Done = false
+	}
+	if req.UntilID > 0 && (c.UntilID == 0 || req.UntilID < c.UntilID) {
+		c.UntilID = req.UntilID
+		c.Done = false
+	}
+}
+
+// ChannelUsername normalizes "@name", "t.me/name" and "https://t.me/name"
+// to "name".
+func ChannelUsername(channel string) string {
+	channel = strings.TrimSpace(channel)
+	for _, prefix := range []string{"https://", "http://", "www.", "t.me/", "telegram.me/", "@"} {
+		channel = strings.TrimPrefix(channel, prefix)
+	}
+	channel, _, _ = strings.Cut(channel, "/")
+	return strings.ToLower(channel)
+}
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"slices"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
@@ -10,7 +12,58 @@ type Message struct {
 	Text      string
 	ChannelID int64
 	MessageID int
-	RawData   any
+	// Date is when the message was posted
+	Date time.Time
+	// EditDate is when the message was last edited, zero if never
+	EditDate time.Time
+	Entities []Entity
+	// Attachments are the attached documents whose text can be read
+	Attachments []Attachment
+	// DocumentText is the text read from Attachments, appended to Text
+	DocumentText string
+	// AlbumID groups the messages of an album, 0 if not part of one
+	AlbumID int64
+	// AlbumMessageIDs are the messages of an album merged into this one
+	AlbumMessageIDs []int
+	RawData         any
+	// Source is the registered chat the message came from, nil if unknown
+	Source *Source
+}
+
+// EntityKind is the type of a message entity: a contact or formatting.
+type EntityKind string
+
+// Entities carrying a contact
+const (
+	EntityMention EntityKind = "mention"
+	EntityURL     EntityKind = "url"
+	EntityTextURL EntityKind = "text_url"
+	EntityEmail   EntityKind = "email"
+	EntityPhone   EntityKind = "phone"
+)
+
+// Formatting entities
+const (
+	EntityBold       EntityKind = "bold"
+	EntityItalic     EntityKind = "italic"
+	EntityUnderline  EntityKind = "underline"
+	EntityStrike     EntityKind = "strike"
+	EntitySpoiler    EntityKind = "spoiler"
+	EntityCode       EntityKind = "code"
+	EntityPre        EntityKind = "pre"
+	EntityBlockquote EntityKind = "blockquote"
+)
+
+// Entity is a marked up part of a message text, e.g. a mention or a link.
+type Entity struct {
+	Kind EntityKind
+	// Text is the marked up text, e.g. "@hr_anna" or "apply here"
+	Text string
+	// URL is the hidden target of text_url entities
+	URL string
+	// Offset and Length locate the entity in the text, in UTF-16 code units
+	Offset int
+	Length int
 }
 
 // KeywordFilter performs pre-LLM filtering based on keywords.
@@ -48,6 +101,23 @@ func NewKeywordFilter() *KeywordFilter {
 	}
 }
 
+// With returns a copy of the filter with the overrides of a source applied.
+func (f *KeywordFilter) With(overrides SourceFilter) *KeywordFilter {
+	filter := *f
+	if overrides.MinLength > 0 {
+		filter.minLength = overrides.MinLength
+	}
+	if len(overrides.Blacklist) > 0 {
+		filter.blacklist = slices.Clone(f.blacklist)
+		for _, kw := range overrides.Blacklist {
+			if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" {
+				filter.blacklist = append(filter.blacklist, kw)
+			}
+		}
+	}
+	return &filter
+}
+
 // ShouldProcess returns true if the message passes keyword filtering.
 func (f *KeywordFilter) ShouldProcess(text string) bool {
 	if utf8.RuneCountInString(text) < f.minLength {
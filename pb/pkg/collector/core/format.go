@@ -0,0 +1,195 @@
+package core
+
+import (
+	"html"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+// RenderHTML renders a message with its formatting and links as HTML, the
+// way Telegram shows it. The text is escaped and only a fixed set of tags
+// and link schemes is produced, so the result is safe to display as is.
+// Overlapping entities are split into properly nested tags.
+func RenderHTML(text string, entities []Entity) string {
+	spans := htmlSpans(entities, len(utf16.Encode([]rune(text))))
+
+	var (
+		b    strings.Builder
+		open []htmlSpan
+		next int
+		pos  int
+	)
+
+	closeEnded := func() {
+		for {
+			// The outermost open span that ends here
+			i := slices.IndexFunc(open, func(s htmlSpan) bool { return s.end <= pos })
+			if i < 0 {
+				return
+			}
+
+			for j := len(open) - 1; j >= i; j-- {
+				b.WriteString(open[j].close)
+			}
+			reopen := slices.DeleteFunc(slices.Clone(open[i:]), func(s htmlSpan) bool { return s.end <= pos })
+			open = append(open[:i], reopen...)
+			for _, s := range reopen {
+				b.WriteString(s.open)
+			}
+		}
+	}
+
+	for _, r := range text {
+		closeEnded()
+		for ; next < len(spans) && spans[next].start <= pos; next++ {
+			// Starting within a surrogate pair it ended already
+			if spans[next].end <= pos {
+				continue
+			}
+			b.WriteString(spans[next].open)
+			open = append(open, spans[next])
+		}
+
+		b.WriteString(html.EscapeString(string(r)))
+		pos += utf16.RuneLen(r)
+	}
+	closeEnded()
+
+	return b.String()
+}
+
+// ExpandLinks writes the targets of hidden links out after their text, e.g.
+// "Apply here (https://forms.gle/...)", so they survive as plain text.
+func ExpandLinks(text string, entities []Entity) string {
+	units := utf16.Encode([]rune(text))
+
+	type insert struct {
+		at   int
+		text string
+	}
+	var inserts []insert
+	for _, e := range entities {
+		if e.Kind != EntityTextURL || e.URL == "" || e.Offset < 0 || e.Length <= 0 || e.Offset+e.Length > len(units) {
+			continue
+		}
+		href, ok := safeLink(e.URL)
+		if !ok || strings.Contains(e.Text, href) {
+			continue
+		}
+		inserts = append(inserts, insert{at: e.Offset + e.Length, text: " (" + href + ")"})
+	}
+	if len(inserts) == 0 {
+		return text
+	}
+	slices.SortStableFunc(inserts, func(a, b insert) int { return a.at - b.at })
+
+	var b strings.Builder
+	last := 0
+	for _, in := range inserts {
+		b.WriteString(string(utf16.Decode(units[last:in.at])))
+		b.WriteString(in.text)
+		last = in.at
+	}
+	b.WriteString(string(utf16.Decode(units[last:])))
+
+	return b.String()
+}
+
+// htmlSpan is an entity rendered as an HTML tag pair.
+type htmlSpan struct {
+	start, end  int
+	open, close string
+}
+
+// htmlSpans converts entities into tag pairs ordered by start, outer first.
+// Entities out of the text's bounds and unsafe links are dropped.
+func htmlSpans(entities []Entity, length int) []htmlSpan {
+	var spans []htmlSpan
+	for _, e := range entities {
+		if e.Offset < 0 || e.Length <= 0 || e.Offset+e.Length > length {
+			continue
+		}
+
+		open, close, ok := htmlTag(e)
+		if !ok {
+			continue
+		}
+		spans = append(spans, htmlSpan{start: e.Offset, end: e.Offset + e.Length, open: open, close: close})
+	}
+
+	slices.SortStableFunc(spans, func(a, b htmlSpan) int {
+		if a.start != b.start {
+			return a.start - b.start
+		}
+		return b.end - a.end
+	})
+	return spans
+}
+
+// htmlTag returns the tags an entity is rendered with.
+func htmlTag(e Entity) (string, string, bool) {
+	switch e.Kind {
+	case EntityBold:
+		return "<b>", "</b>", true
+	case EntityItalic:
+		return "<i>", "</i>", true
+	case EntityUnderline:
+		return "<u>", "</u>", true
+	case EntityStrike:
+		return "<s>", "</s>", true
+	case EntitySpoiler:
+		return `<span class="tg-spoiler">`, "</span>", true
+	case EntityCode:
+		return "<code>", "</code>", true
+	case EntityPre:
+		return "<pre>", "</pre>", true
+	case EntityBlockquote:
+		return "<blockquote>", "</blockquote>", true
+	}
+
+	href, ok := entityLink(e)
+	if !ok {
+		return "", "", false
+	}
+	return `<a href="` + html.EscapeString(href) + `" target="_blank" rel="noopener noreferrer nofollow">`, "</a>", true
+}
+
+// entityLink returns where a link, mention, email or phone entity leads.
+func entityLink(e Entity) (string, bool) {
+	switch e.Kind {
+	case EntityMention:
+		username := strings.TrimPrefix(e.Text, "@")
+		if username == "" {
+			return "", false
+		}
+		return "https://t.me/" + username, true
+	case EntityEmail:
+		return "mailto:" + e.Text, e.Text != ""
+	case EntityPhone:
+		return "tel:" + e.Text, e.Text != ""
+	case EntityURL:
+		link := e.Text
+		if !strings.Contains(link, "://") {
+			link = "https://" + link
+		}
+		return safeLink(link)
+	case EntityTextURL:
+		return safeLink(e.URL)
+	}
+	return "", false
+}
+
+// safeLink accepts web and Telegram links only, no javascript: and the like.
+func safeLink(link string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "tg", "mailto", "tel":
+		return u.String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,137 @@
+package core
+
+import "testing"
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		entities []Entity
+		want     string
+	}{
+		{
+			name: "plain text escaped",
+			text: "<b>Go</b> & Rust",
+			want: "&lt;b&gt;Go&lt;/b&gt; &amp; Rust",
+		},
+		{
+			name:     "bold",
+			text:     "Senior Go",
+			entities: []Entity{{Kind: EntityBold, Offset: 0, Length: 6}},
+			want:     "<b>Senior</b> Go",
+		},
+		{
+			name: "nested",
+			text: "Senior Go",
+			entities: []Entity{
+				{Kind: EntityItalic, Offset: 7, Length: 2},
+				{Kind: EntityBold, Offset: 0, Length: 9},
+			},
+			want: "<b>Senior <i>Go</i></b>",
+		},
+		{
+			name: "overlapping split",
+			text: "abcdef",
+			entities: []Entity{
+				{Kind: EntityBold, Offset: 0, Length: 4},
+				{Kind: EntityItalic, Offset: 2, Length: 4},
+			},
+			want: "<b>ab<i>cd</i></b><i>ef</i>",
+		},
+		{
+			name:     "offsets in utf-16 units",
+			text:     "🔥 Go",
+			entities: []Entity{{Kind: EntityBold, Offset: 3, Length: 2}},
+			want:     "🔥 <b>Go</b>",
+		},
+		{
+			name:     "hidden link",
+			text:     "Apply here",
+			entities: []Entity{{Kind: EntityTextURL, Text: "here", URL: "https://acme.io/jobs?a=1&b=2", Offset: 6, Length: 4}},
+			want:     `Apply <a href="https://acme.io/jobs?a=1&amp;b=2" target="_blank" rel="noopener noreferrer nofollow">here</a>`,
+		},
+		{
+			name:     "mention",
+			text:     "Write @hr_anna",
+			entities: []Entity{{Kind: EntityMention, Text: "@hr_anna", Offset: 6, Length: 8}},
+			want:     `Write <a href="https://t.me/hr_anna" target="_blank" rel="noopener noreferrer nofollow">@hr_anna</a>`,
+		},
+		{
+			name:     "unsafe link dropped",
+			text:     "click",
+			entities: []Entity{{Kind: EntityTextURL, Text: "click", URL: "javascript:alert(1)", Offset: 0, Length: 5}},
+			want:     "click",
+		},
+		{
+			name:     "out of bounds dropped",
+			text:     "Go",
+			entities: []Entity{{Kind: EntityBold, Offset: 1, Length: 5}},
+			want:     "Go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderHTML(tt.text, tt.entities); got != tt.want {
+				t.Errorf("RenderHTML(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		entities []Entity
+		want     string
+	}{
+		{
+			name:     "hidden link written out",
+			text:     "Apply here please",
+			entities: []Entity{{Kind: EntityTextURL, Text: "here", URL: "https://forms.gle/abc", Offset: 6, Length: 4}},
+			want:     "Apply here (https://forms.gle/abc) please",
+		},
+		{
+			name: "several links",
+			text: "CV or form",
+			entities: []Entity{
+				{Kind: EntityTextURL, Text: "form", URL: "https://forms.gle/abc", Offset: 6, Length: 4},
+				{Kind: EntityTextURL, Text: "CV", URL: "mailto:hr@acme.io", Offset: 0, Length: 2},
+			},
+			want: "CV (mailto:hr@acme.io) or form (https://forms.gle/abc)",
+		},
+		{
+			name:     "after emoji",
+			text:     "🔥 apply",
+			entities: []Entity{{Kind: EntityTextURL, Text: "apply", URL: "https://acme.io", Offset: 3, Length: 5}},
+			want:     "🔥 apply (https://acme.io)",
+		},
+		{
+			name:     "link already visible",
+			text:     "https://acme.io",
+			entities: []Entity{{Kind: EntityTextURL, Text: "https://acme.io", URL: "https://acme.io", Offset: 0, Length: 15}},
+			want:     "https://acme.io",
+		},
+		{
+			name:     "plain url entity kept",
+			text:     "acme.io",
+			entities: []Entity{{Kind: EntityURL, Text: "acme.io", Offset: 0, Length: 7}},
+			want:     "acme.io",
+		},
+		{
+			name:     "unsafe link dropped",
+			text:     "click",
+			entities: []Entity{{Kind: EntityTextURL, Text: "click", URL: "javascript:alert(1)", Offset: 0, Length: 5}},
+			want:     "click",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandLinks(tt.text, tt.entities); got != tt.want {
+				t.Errorf("ExpandLinks(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
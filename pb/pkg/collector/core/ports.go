@@ -2,8 +2,107 @@ package core
 
 import "context"
 
+// Stats counts messages handled by the collector since start.
+type Stats struct {
+	Received int64 `json:"received"`
+	// Filtered were dropped by the keyword filter
+	Filtered int64 `json:"filtered"`
+	// Duplicates were collected before, e.g. delivered twice
+	Duplicates int64 `json:"duplicates"`
+	// Submitted created new jobs
+	Submitted int64 `json:"submitted"`
+	// Reposts were recorded as sightings of existing jobs
+	Reposts int64 `json:"reposts"`
+	// Edited changed the text of a job
+	Edited int64 `json:"edited"`
+	// Closed marked a job's vacancy as taken
+	Closed int64 `json:"closed"`
+	// Withdrawn jobs had all their posts deleted
+	Withdrawn int64 `json:"withdrawn"`
+	Failed    int64 `json:"failed"`
+}
+
 // CollectorService handles incoming messages from sources.
 type CollectorService interface {
 	// Handle processes an incoming message.
 	Handle(ctx context.Context, msg Message) error
+
+	// HandleEdit processes an edited message. Edits of messages never
+	// collected, e.g. filtered out before the edit, are handled as new.
+	HandleEdit(ctx context.Context, msg Message) error
+
+	// HasDocuments reports whether the job of a message holds the documents
+	// with the given ids, so that an edit doesn't download them again.
+	HasDocuments(ctx context.Context, channelID int64, messageID int, ids []string) bool
+
+	// HandleDelete processes messages deleted from a channel.
+	HandleDelete(ctx context.Context, channelID int64, messageIDs []int) error
+
+	// Albums returns the messages of the collected albums the given messages
+	// of a channel are part of, so that an album losing some of its messages
+	// is collected again from the rest.
+	Albums(ctx context.Context, channelID int64, messageIDs []int) [][]int
+
+	// Stats returns message counters since start.
+	Stats() Stats
+}
+
+// Backfiller collects the history of channels subscribed to after the fact.
+type Backfiller interface {
+	// Backfill pages through the history of req.Channel from its saved
+	// cursor back to req.Until/req.UntilID, feeding every message through
+	// CollectorService.Handle. Progress is saved after every page.
+	Backfill(ctx context.Context, req BackfillRequest) (BackfillCursor, error)
+}
+
+// BackfillCursors persists backfill progress per channel.
+type BackfillCursors interface {
+	// Get returns the cursor of a channel, nil if it was never backfilled.
+	Get(ctx context.Context, channel string) (*BackfillCursor, error)
+
+	// Save creates or updates the cursor of a channel.
+	Save(ctx context.Context, cursor BackfillCursor) error
+
+	// List returns all cursors, most recently updated first.
+	List(ctx context.Context) ([]BackfillCursor, error)
+}
+
+// PendingAlbums persists the parts of albums waiting for the rest. An
+// update counts as handled once its part is buffered, so an album lost from
+// the buffer, e.g. by a crash, is collected again from here.
+type PendingAlbums interface {
+	// Add records a part of an album.
+	Add(ctx context.Context, peerID, albumID int64, messageID int) error
+
+	// Remove forgets an album once it is collected.
+	Remove(ctx context.Context, peerID, albumID int64) error
+
+	// List returns all pending albums, oldest first.
+	List(ctx context.Context) ([]PendingAlbum, error)
+}
+
+// SourceRegistry decides which chats are collected.
+type SourceRegistry interface {
+	// Resolve returns the registered source of a seen chat. Chats seen for
+	// the first time are registered disabled and pending review; changed
+	// titles and usernames are saved.
+	Resolve(ctx context.Context, seen Source) (Source, error)
+
+	// Find returns the source of a peer id or username, ErrSourceNotFound
+	// if it is not registered.
+	Find(ctx context.Context, ref SourceRef) (Source, error)
+
+	// List returns registered sources by priority, only the ones pending
+	// review if pendingOnly is set.
+	List(ctx context.Context, pendingOnly bool) ([]Source, error)
+
+	// Save creates or updates a source.
+	Save(ctx context.Context, source Source) (Source, error)
+}
+
+// DocumentReader extracts the text of attached documents.
+type DocumentReader interface {
+	// Read returns the plain text of a document, ErrUnsupportedDocument if
+	// its type can't be read.
+	Read(ctx context.Context, doc Attachment) (string, error)
 }
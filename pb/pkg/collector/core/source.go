@@ -0,0 +1,101 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrSourceNotFound is returned for chats missing from the source registry.
+var ErrSourceNotFound = errors.New("source not found")
+
+// SourceType is the kind of chat a source is.
+type SourceType string
+
+const (
+	SourceChannel    SourceType = "channel"
+	SourceSupergroup SourceType = "supergroup"
+	SourceGroup      SourceType = "group"
+	SourceBot        SourceType = "bot"
+)
+
+// Who added a source. Sources added in the admin UI carry free text.
+const (
+	AddedByCollector = "collector"
+	AddedByCLI       = "cli"
+)
+
+// Source is a chat registered for collection. Only enabled sources are
+// ingested; chats seen for the first time are registered pending review.
+type Source struct {
+	ID       string
+	PeerID   int64
+	Type     SourceType
+	Title    string
+	Username string
+	Enabled  bool
+	// Pending sources were registered automatically and were never reviewed
+	Pending bool
+	// Priority orders extraction of the source's jobs, higher first
+	Priority int
+	Filter   SourceFilter
+	AddedBy  string
+}
+
+// SourceFilter overrides the keyword filter for a source. Zero values keep
+// the defaults.
+type SourceFilter struct {
+	// Bypass ingests every message, e.g. of a channel posting only vacancies
+	Bypass bool `json:"bypass,omitempty"`
+	// MinLength replaces the minimum message length
+	MinLength int `json:"minLength,omitempty"`
+	// Blacklist adds keywords that drop a message
+	Blacklist []string `json:"blacklist,omitempty"`
+}
+
+// Label returns a human readable name of the source.
+func (s Source) Label() string {
+	switch {
+	case s.Username != "":
+		return "@" + s.Username
+	case s.Title != "":
+		return s.Title
+	default:
+		return strconv.FormatInt(s.PeerID, 10)
+	}
+}
+
+// SourceRef identifies a source by peer id or username, as given on the
+// command line: "1234567890", "@golang_jobs" or "t.me/golang_jobs".
+type SourceRef struct {
+	PeerID   int64
+	Username string
+}
+
+// ParseSourceRef parses a peer id or a username.
+func ParseSourceRef(s string) (SourceRef, error) {
+	s = strings.TrimSpace(s)
+	if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return SourceRef{PeerID: id}, nil
+	}
+	if username := ChannelUsername(s); username != "" {
+		return SourceRef{Username: username}, nil
+	}
+	return SourceRef{}, fmt.Errorf("invalid source %q: expected a peer id or a username", s)
+}
+
+// PostURL returns the link to a message of the source. Public channels and
+// supergroups link by username, private ones by id, which only opens for
+// members. Legacy groups and bot chats have no message links, so false is
+// returned for them.
+func (s Source) PostURL(messageID int) (string, bool) {
+	switch s.Type {
+	case SourceGroup, SourceBot:
+		return "", false
+	}
+	if s.Username != "" {
+		return fmt.Sprintf("https://t.me/%s/%d", s.Username, messageID), true
+	}
+	return fmt.Sprintf("https://t.me/c/%d/%d", s.PeerID, messageID), true
+}
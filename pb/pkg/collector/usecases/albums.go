@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	pbcore "github.com/pocketbase/pocketbase/core"
+
+	"svpb-tmpl/pkg/collector/core"
+)
+
+// PendingAlbums implements core.PendingAlbums on the pendingAlbums
+// collection.
+type PendingAlbums struct {
+	app *pocketbase.PocketBase
+
+	// mu serializes read-modify-write of records; parts of an album may be
+	// delivered concurrently
+	mu sync.Mutex
+}
+
+// NewPendingAlbums creates a new pending album store.
+func NewPendingAlbums(app *pocketbase.PocketBase) *PendingAlbums {
+	return &PendingAlbums{app: app}
+}
+
+// Add records a part of an album.
+func (p *PendingAlbums) Add(ctx context.Context, peerID, albumID int64, messageID int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	record, err := p.find(peerID, albumID)
+	if errors.Is(err, sql.ErrNoRows) {
+		collection, err := p.app.FindCollectionByNameOrId("pendingAlbums")
+		if err != nil {
+			return fmt.Errorf("pendingAlbums collection not found: %w", err)
+		}
+		record = pbcore.NewRecord(collection)
+		record.Set("peerId", strconv.FormatInt(peerID, 10))
+		record.Set("albumId", strconv.FormatInt(albumID, 10))
+	} else if err != nil {
+		return fmt.Errorf("failed to find pending album: %w", err)
+	}
+
+	ids := pendingMessageIDs(record)
+	if slices.Contains(ids, messageID) {
+		return nil
+	}
+	record.Set("messageIds", append(ids, messageID))
+
+	if err := p.app.Save(record); err != nil {
+		return fmt.Errorf("failed to save pending album: %w", err)
+	}
+	return nil
+}
+
+// Remove forgets an album.
+func (p *PendingAlbums) Remove(ctx context.Context, peerID, albumID int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	record, err := p.find(peerID, albumID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find pending album: %w", err)
+	}
+
+	if err := p.app.Delete(record); err != nil {
+		return fmt.Errorf("failed to delete pending album: %w", err)
+	}
+	return nil
+}
+
+// List returns all pending albums, oldest first.
+func (p *PendingAlbums) List(ctx context.Context) ([]core.PendingAlbum, error) {
+	records, err := p.app.FindRecordsByFilter("pendingAlbums", "", "created", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending albums: %w", err)
+	}
+
+	albums := make([]core.PendingAlbum, 0, len(records))
+	for _, record := range records {
+		peerID, _ := strconv.ParseInt(record.GetString("peerId"), 10, 64)
+		albumID, _ := strconv.ParseInt(record.GetString("albumId"), 10, 64)
+		albums = append(albums, core.PendingAlbum{
+			PeerID:     peerID,
+			AlbumID:    albumID,
+			MessageIDs: pendingMessageIDs(record),
+		})
+	}
+	return albums, nil
+}
+
+func (p *PendingAlbums) find(peerID, albumID int64) (*pbcore.Record, error) {
+	return p.app.FindFirstRecordByFilter(
+		"pendingAlbums",
+		"peerId = {:peerId} && albumId = {:albumId}",
+		dbx.Params{
+			"peerId":  strconv.FormatInt(peerID, 10),
+			"albumId": strconv.FormatInt(albumID, 10),
+		},
+	)
+}
+
+func pendingMessageIDs(record *pbcore.Record) []int {
+	var ids []int
+	_ = record.UnmarshalJSONField("messageIds", &ids)
+	return ids
+}
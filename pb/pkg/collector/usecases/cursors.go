@@ -0,0 +1,105 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	pbcore "github.com/pocketbase/pocketbase/core"
+
+	"svpb-tmpl/pkg/collector/core"
+)
+
+// Cursors implements core.BackfillCursors on the backfillCursors collection.
+type Cursors struct {
+	app *pocketbase.PocketBase
+}
+
+// NewCursors creates a new backfill cursor store.
+func NewCursors(app *pocketbase.PocketBase) *Cursors {
+	return &Cursors{app: app}
+}
+
+// Get returns the cursor of a channel, nil if it was never backfilled.
+func (c *Cursors) Get(ctx context.Context, channel string) (*core.BackfillCursor, error) {
+	record, err := c.find(channel)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cursor: %w", err)
+	}
+
+	cursor := toCursor(record)
+	return &cursor, nil
+}
+
+// Save creates or updates the cursor of a channel.
+func (c *Cursors) Save(ctx context.Context, cursor core.BackfillCursor) error {
+	record, err := c.find(cursor.Channel)
+	if errors.Is(err, sql.ErrNoRows) {
+		collection, err := c.app.FindCollectionByNameOrId("backfillCursors")
+		if err != nil {
+			return fmt.Errorf("backfillCursors collection not found: %w", err)
+		}
+		record = pbcore.NewRecord(collection)
+		record.Set("channel", cursor.Channel)
+	} else if err != nil {
+		return fmt.Errorf("failed to find cursor: %w", err)
+	}
+
+	record.Set("channelId", strconv.FormatInt(cursor.ChannelID, 10))
+	record.Set("offsetId", cursor.OffsetID)
+	record.Set("until", cursor.Until)
+	record.Set("untilId", cursor.UntilID)
+	record.Set("messages", cursor.Messages)
+	record.Set("done", cursor.Done)
+	record.Set("lastError", cursor.LastError)
+
+	if err := c.app.Save(record); err != nil {
+		return fmt.Errorf("failed to save cursor: %w", err)
+	}
+	return nil
+}
+
+// List returns all cursors, most recently updated first.
+func (c *Cursors) List(ctx context.Context) ([]core.BackfillCursor, error) {
+	records, err := c.app.FindRecordsByFilter("backfillCursors", "", "-updated", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cursors: %w", err)
+	}
+
+	cursors := make([]core.BackfillCursor, 0, len(records))
+	for _, record := range records {
+		cursors = append(cursors, toCursor(record))
+	}
+	return cursors, nil
+}
+
+func (c *Cursors) find(channel string) (*pbcore.Record, error) {
+	return c.app.FindFirstRecordByFilter(
+		"backfillCursors",
+		"channel = {:channel}",
+		dbx.Params{"channel": channel},
+	)
+}
+
+func toCursor(record *pbcore.Record) core.BackfillCursor {
+	channelID, _ := strconv.ParseInt(record.GetString("channelId"), 10, 64)
+
+	return core.BackfillCursor{
+		Channel:   record.GetString("channel"),
+		ChannelID: channelID,
+		OffsetID:  record.GetInt("offsetId"),
+		Until:     record.GetDateTime("until").Time(),
+		UntilID:   record.GetInt("untilId"),
+		Messages:  record.GetInt("messages"),
+		Done:      record.GetBool("done"),
+		LastError: record.GetString("lastError"),
+		Updated:   record.GetDateTime("updated").Time(),
+	}
+}
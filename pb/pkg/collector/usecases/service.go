@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"unicode/utf8"
 
 	"svpb-tmpl/pkg/collector/core"
@@ -13,17 +15,32 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxTextLength is the number of characters a job's original text holds.
+const maxTextLength = 5000
+
 // Service implements core.CollectorService.
 type Service struct {
 	jobService jobcore.JobService
+	documents  core.DocumentReader
 	filter     *core.KeywordFilter
 	logger     *zap.Logger
+
+	received   atomic.Int64
+	filtered   atomic.Int64
+	duplicates atomic.Int64
+	submitted  atomic.Int64
+	reposts    atomic.Int64
+	edited     atomic.Int64
+	closed     atomic.Int64
+	withdrawn  atomic.Int64
+	failed     atomic.Int64
 }
 
 // NewService creates a new CollectorService implementation.
-func NewService(jobService jobcore.JobService, logger *zap.Logger) *Service {
+func NewService(jobService jobcore.JobService, documents core.DocumentReader, logger *zap.Logger) *Service {
 	return &Service{
 		jobService: jobService,
+		documents:  documents,
 		filter:     core.NewKeywordFilter(),
 		logger:     logger,
 	}
@@ -31,12 +48,19 @@ func NewService(jobService jobcore.JobService, logger *zap.Logger) *Service {
 
 // Handle processes an incoming message.
 func (s *Service) Handle(ctx context.Context, msg core.Message) error {
+	return s.handle(ctx, s.withDocumentText(ctx, msg))
+}
+
+// handle processes a message whose document was read.
+func (s *Service) handle(ctx context.Context, msg core.Message) error {
 	if msg.Text == "" {
 		return nil
 	}
+	s.received.Add(1)
 
-	// Pre-filter by keywords
-	if !s.filter.ShouldProcess(msg.Text) {
+	// Pre-filter by keywords, unless the source posts nothing but vacancies
+	if !s.shouldProcess(msg) {
+		s.filtered.Add(1)
 		s.logger.Debug("Message filtered out by keywords",
 			zap.Int64("channelId", msg.ChannelID),
 			zap.Int("msgId", msg.MessageID),
@@ -50,35 +74,52 @@ func (s *Service) Handle(ctx context.Context, msg core.Message) error {
 		zap.Int("runesCount", utf8.RuneCountInString(msg.Text)),
 	)
 
-	// Calculate hash for deduplication
-	hash := s.calculateHash(msg.Text)
-
-	// Check for duplicates
-	isDuplicate, err := s.jobService.CheckDuplicate(ctx, msg.ChannelID, msg.MessageID, hash)
+	// Submit raw job. Messages collected before, even by a concurrent
+	// delivery, resolve to their existing job.
+	result, err := s.jobService.SubmitRaw(ctx, s.rawJobInput(msg))
 	if err != nil {
-		s.logger.Error("Failed to check duplicate", zap.Error(err))
+		s.failed.Add(1)
+		s.logger.Error("Failed to submit raw job",
+			zap.Error(err),
+			zap.Int64("channelId", msg.ChannelID),
+			zap.Int("msgId", msg.MessageID),
+		)
+		return nil
 	}
-	if isDuplicate {
+
+	switch {
+	case result.Created:
+		s.submitted.Add(1)
+		s.logger.Info("Raw job submitted successfully",
+			zap.String("jobId", result.JobID),
+			zap.Int64("channelId", msg.ChannelID),
+			zap.Int("msgId", msg.MessageID),
+		)
+	case result.Repost:
+		s.reposts.Add(1)
+	default:
+		s.duplicates.Add(1)
 		s.logger.Debug("Duplicate message, skipping",
+			zap.String("jobId", result.JobID),
 			zap.Int64("channelId", msg.ChannelID),
 			zap.Int("msgId", msg.MessageID),
-			zap.String("hash", hash),
 		)
-		return nil
 	}
 
-	// Submit raw job
-	input := jobcore.RawJobInput{
-		OriginalText: msg.Text,
-		ChannelID:    msg.ChannelID,
-		MessageID:    msg.MessageID,
-		Hash:         hash,
-		RawData:      msg.RawData,
+	return nil
+}
+
+// HandleEdit processes an edited message.
+func (s *Service) HandleEdit(ctx context.Context, msg core.Message) error {
+	msg = s.withDocumentText(ctx, s.withStoredDocuments(ctx, msg))
+	if msg.Text == "" {
+		return nil
 	}
 
-	jobID, err := s.jobService.SubmitRaw(ctx, input)
+	result, err := s.jobService.Edit(ctx, s.rawJobInput(msg))
 	if err != nil {
-		s.logger.Error("Failed to submit raw job",
+		s.failed.Add(1)
+		s.logger.Error("Failed to apply message edit",
 			zap.Error(err),
 			zap.Int64("channelId", msg.ChannelID),
 			zap.Int("msgId", msg.MessageID),
@@ -86,18 +127,247 @@ func (s *Service) Handle(ctx context.Context, msg core.Message) error {
 		return nil
 	}
 
-	s.logger.Info("Raw job submitted successfully",
-		zap.String("jobId", jobID),
-		zap.Int64("channelId", msg.ChannelID),
-		zap.Int("msgId", msg.MessageID),
-	)
+	// The edit may have made a filtered out message a vacancy. A part of an
+	// album is only collected with the rest of the album.
+	if result.JobID == "" {
+		if msg.AlbumID != 0 && len(msg.AlbumMessageIDs) == 0 {
+			return nil
+		}
+		return s.handle(ctx, msg)
+	}
+
+	if result.Updated {
+		s.edited.Add(1)
+	}
+	if result.Closed {
+		s.closed.Add(1)
+	}
+
+	return nil
+}
+
+// HandleDelete processes messages deleted from a channel.
+func (s *Service) HandleDelete(ctx context.Context, channelID int64, messageIDs []int) error {
+	withdrawn, err := s.jobService.Withdraw(ctx, channelID, messageIDs)
+	if err != nil {
+		s.failed.Add(1)
+		s.logger.Error("Failed to withdraw jobs of deleted messages",
+			zap.Error(err),
+			zap.Int64("channelId", channelID),
+			zap.Ints("msgIds", messageIDs),
+		)
+		return nil
+	}
 
+	s.withdrawn.Add(int64(withdrawn))
 	return nil
 }
 
+// Albums returns the messages of the collected albums the messages are part of.
+func (s *Service) Albums(ctx context.Context, channelID int64, messageIDs []int) [][]int {
+	albums, err := s.jobService.Albums(ctx, channelID, messageIDs)
+	if err != nil {
+		s.logger.Warn("Failed to find albums of messages",
+			zap.Error(err),
+			zap.Int64("channelId", channelID),
+			zap.Ints("msgIds", messageIDs),
+		)
+		return nil
+	}
+	return albums
+}
+
+// HasDocuments reports whether the job of a message holds the documents.
+func (s *Service) HasDocuments(ctx context.Context, channelID int64, messageID int, ids []string) bool {
+	docs, err := s.jobService.Documents(ctx, channelID, messageID)
+	if err != nil {
+		s.logger.Warn("Failed to find stored documents",
+			zap.Error(err),
+			zap.Int64("channelId", channelID),
+			zap.Int("msgId", messageID),
+		)
+		return false
+	}
+	return len(ids) > 0 && slices.Equal(docs.IDs, ids)
+}
+
+// withStoredDocuments appends the text of documents the message's job
+// already holds, which an edit doesn't download again.
+func (s *Service) withStoredDocuments(ctx context.Context, msg core.Message) core.Message {
+	if !slices.ContainsFunc(msg.Attachments, func(doc core.Attachment) bool { return doc.Data == nil }) {
+		return msg
+	}
+
+	docs, err := s.jobService.Documents(ctx, msg.ChannelID, msg.MessageID)
+	if err != nil {
+		s.logger.Warn("Failed to find stored documents",
+			zap.Error(err),
+			zap.Int64("channelId", msg.ChannelID),
+			zap.Int("msgId", msg.MessageID),
+		)
+		return msg
+	}
+
+	ids := make([]string, 0, len(msg.Attachments))
+	for _, doc := range msg.Attachments {
+		ids = append(ids, doc.ID)
+	}
+	if !slices.Equal(docs.IDs, ids) {
+		return msg
+	}
+
+	appendDocument(&msg, docs.Text)
+	return msg
+}
+
+// withDocumentText appends the text of attached documents to the caption,
+// so that a vacancy posted as a PDF is filtered and extracted like a text
+// post. Documents that can't be read are skipped.
+func (s *Service) withDocumentText(ctx context.Context, msg core.Message) core.Message {
+	if s.documents == nil {
+		return msg
+	}
+
+	for _, doc := range msg.Attachments {
+		// Held by the job, see withStoredDocuments
+		if doc.Data == nil {
+			continue
+		}
+
+		text, err := s.documents.Read(ctx, doc)
+		if err != nil {
+			s.logger.Warn("Failed to read attached document",
+				zap.Error(err),
+				zap.Int64("channelId", msg.ChannelID),
+				zap.Int("msgId", msg.MessageID),
+				zap.String("name", doc.Name),
+			)
+			continue
+		}
+
+		appendDocument(&msg, text)
+	}
+	return msg
+}
+
+// appendDocument appends the text of a document to the message text and its
+// document text. Caption and documents stay within the length of a job's
+// original text.
+func appendDocument(msg *core.Message, text string) {
+	budget := maxTextLength - utf8.RuneCountInString(msg.Text) - 2
+	if runes := []rune(text); len(runes) > budget {
+		text = string(runes[:max(budget, 0)])
+	}
+	if text = strings.TrimSpace(text); text == "" {
+		return
+	}
+
+	msg.Text = joinText(msg.Text, text)
+	msg.DocumentText = joinText(msg.DocumentText, text)
+}
+
+// joinText joins two texts with a blank line between.
+func joinText(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "\n\n" + b
+}
+
+// shouldProcess applies the keyword filter with the overrides of the
+// message's source.
+func (s *Service) shouldProcess(msg core.Message) bool {
+	if msg.Source == nil {
+		return s.filter.ShouldProcess(msg.Text)
+	}
+	if msg.Source.Filter.Bypass {
+		return true
+	}
+	return s.filter.With(msg.Source.Filter).ShouldProcess(msg.Text)
+}
+
+// rawJobInput converts a message into job module input.
+func (s *Service) rawJobInput(msg core.Message) jobcore.RawJobInput {
+	source := core.Source{PeerID: msg.ChannelID}
+	if msg.Source != nil {
+		source = *msg.Source
+	}
+	url, _ := source.PostURL(msg.MessageID)
+
+	return jobcore.RawJobInput{
+		OriginalText:    msg.Text,
+		HTML:            core.RenderHTML(msg.Text, msg.Entities),
+		ExpandedText:    core.ExpandLinks(msg.Text, msg.Entities),
+		ChannelID:       msg.ChannelID,
+		MessageID:       msg.MessageID,
+		URL:             url,
+		Hash:            s.calculateHash(msg.Text),
+		RawData:         msg.RawData,
+		PostedAt:        msg.Date,
+		EditedAt:        msg.EditDate,
+		Contacts:        contactsFromEntities(msg.Entities),
+		Priority:        source.Priority,
+		Attachments:     attachments(msg.Attachments),
+		DocumentText:    msg.DocumentText,
+		AlbumID:         msg.AlbumID,
+		AlbumMessageIDs: msg.AlbumMessageIDs,
+	}
+}
+
+// attachments converts attached documents into job attachments.
+func attachments(docs []core.Attachment) []jobcore.Attachment {
+	var converted []jobcore.Attachment
+	for _, doc := range docs {
+		converted = append(converted, jobcore.Attachment{ID: doc.ID, Name: doc.Name, Data: doc.Data})
+	}
+	return converted
+}
+
+// Stats returns message counters since start.
+func (s *Service) Stats() core.Stats {
+	return core.Stats{
+		Received:   s.received.Load(),
+		Filtered:   s.filtered.Load(),
+		Duplicates: s.duplicates.Load(),
+		Submitted:  s.submitted.Load(),
+		Reposts:    s.reposts.Load(),
+		Edited:     s.edited.Load(),
+		Closed:     s.closed.Load(),
+		Withdrawn:  s.withdrawn.Load(),
+		Failed:     s.failed.Load(),
+	}
+}
+
 // calculateHash creates a normalized hash of the message text.
 func (s *Service) calculateHash(text string) string {
 	normalized := strings.ToLower(strings.Join(strings.Fields(text), ""))
 	sum := sha256.Sum256([]byte(normalized))
 	return hex.EncodeToString(sum[:])
 }
+
+// contactsFromEntities converts mentions, links, emails and phones marked up
+// by Telegram into job contacts. Invalid ones are dropped by the job module.
+func contactsFromEntities(entities []core.Entity) []jobcore.Contact {
+	var contacts []jobcore.Contact
+
+	for _, entity := range entities {
+		switch entity.Kind {
+		case core.EntityMention:
+			contacts = append(contacts, jobcore.Contact{Kind: jobcore.ContactTelegram, Value: entity.Text})
+		case core.EntityEmail:
+			contacts = append(contacts, jobcore.Contact{Kind: jobcore.ContactEmail, Value: entity.Text})
+		case core.EntityPhone:
+			contacts = append(contacts, jobcore.Contact{Kind: jobcore.ContactPhone, Value: entity.Text})
+		case core.EntityURL, core.EntityTextURL:
+			link := entity.URL
+			if link == "" {
+				link = entity.Text
+			}
+			if contact, ok := jobcore.ParseLink(link); ok {
+				contacts = append(contacts, contact)
+			}
+		}
+	}
+
+	return contacts
+}
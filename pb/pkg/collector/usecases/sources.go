@@ -0,0 +1,212 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"go.uber.org/zap"
+
+	"svpb-tmpl/pkg/collector/core"
+	jobcore "svpb-tmpl/pkg/job/core"
+)
+
+// Sources implements core.SourceRegistry on the sources collection. Sources
+// are read on every message, so changes made in the admin UI or by the
+// tg-sources command apply without a restart.
+type Sources struct {
+	app        *pocketbase.PocketBase
+	jobService jobcore.JobService
+	logger     *zap.Logger
+}
+
+// NewSources creates a new source registry. Jobs of a source are relinked
+// when its username or type changes.
+func NewSources(app *pocketbase.PocketBase, jobService jobcore.JobService, logger *zap.Logger) *Sources {
+	return &Sources{app: app, jobService: jobService, logger: logger}
+}
+
+// Resolve returns the registered source of a seen chat, registering it
+// pending review if it is new.
+func (s *Sources) Resolve(ctx context.Context, seen core.Source) (core.Source, error) {
+	record, err := s.findByPeer(seen.PeerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		seen.Enabled = false
+		seen.Pending = true
+		seen.AddedBy = core.AddedByCollector
+
+		source, err := s.Save(ctx, seen)
+		if err != nil {
+			// Registered by a concurrent update of the same chat
+			if record, findErr := s.findByPeer(seen.PeerID); findErr == nil {
+				return toSource(record), nil
+			}
+			return core.Source{}, err
+		}
+
+		s.logger.Info("New source pending review",
+			zap.Int64("peerId", source.PeerID),
+			zap.String("source", source.Label()),
+			zap.String("type", string(source.Type)),
+		)
+		return source, nil
+	}
+	if err != nil {
+		return core.Source{}, fmt.Errorf("failed to find source: %w", err)
+	}
+
+	source := toSource(record)
+	if !refresh(&source, seen) {
+		return source, nil
+	}
+	return s.Save(ctx, source)
+}
+
+// Find returns the source of a peer id or username.
+func (s *Sources) Find(ctx context.Context, ref core.SourceRef) (core.Source, error) {
+	var (
+		record *pbcore.Record
+		err    error
+	)
+	if ref.Username != "" {
+		record, err = s.app.FindFirstRecordByFilter(
+			"sources",
+			"username = {:username}",
+			dbx.Params{"username": ref.Username},
+		)
+	} else {
+		record, err = s.findByPeer(ref.PeerID)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Source{}, core.ErrSourceNotFound
+	}
+	if err != nil {
+		return core.Source{}, fmt.Errorf("failed to find source: %w", err)
+	}
+
+	return toSource(record), nil
+}
+
+// List returns registered sources by priority.
+func (s *Sources) List(ctx context.Context, pendingOnly bool) ([]core.Source, error) {
+	filter := ""
+	if pendingOnly {
+		filter = "pending = true"
+	}
+
+	records, err := s.app.FindRecordsByFilter("sources", filter, "-priority,-enabled,title", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sources: %w", err)
+	}
+
+	sources := make([]core.Source, 0, len(records))
+	for _, record := range records {
+		sources = append(sources, toSource(record))
+	}
+	return sources, nil
+}
+
+// Save creates or updates a source.
+func (s *Sources) Save(ctx context.Context, source core.Source) (core.Source, error) {
+	var record *pbcore.Record
+	if source.ID != "" {
+		found, err := s.app.FindRecordById("sources", source.ID)
+		if err != nil {
+			return core.Source{}, fmt.Errorf("failed to find source: %w", err)
+		}
+		record = found
+	} else {
+		collection, err := s.app.FindCollectionByNameOrId("sources")
+		if err != nil {
+			return core.Source{}, fmt.Errorf("sources collection not found: %w", err)
+		}
+		record = pbcore.NewRecord(collection)
+		record.Set("peerId", strconv.FormatInt(source.PeerID, 10))
+		record.Set("addedBy", source.AddedBy)
+	}
+
+	relink := record.IsNew() ||
+		record.GetString("type") != string(source.Type) ||
+		record.GetString("username") != source.Username
+
+	record.Set("type", string(source.Type))
+	record.Set("title", source.Title)
+	record.Set("username", source.Username)
+	record.Set("enabled", source.Enabled)
+	record.Set("pending", source.Pending)
+	record.Set("priority", source.Priority)
+	record.Set("filter", source.Filter)
+
+	if err := s.app.Save(record); err != nil {
+		return core.Source{}, fmt.Errorf("failed to save source: %w", err)
+	}
+
+	saved := toSource(record)
+	if relink {
+		s.relink(ctx, saved)
+	}
+	return saved, nil
+}
+
+// relink updates the links of the jobs of a source whose username or type
+// changed. Failures are logged and don't fail the save.
+func (s *Sources) relink(ctx context.Context, source core.Source) {
+	if _, err := s.jobService.Relink(ctx, source.PeerID, source.PostURL); err != nil {
+		s.logger.Warn("Failed to relink jobs of source",
+			zap.String("source", source.Label()),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *Sources) findByPeer(peerID int64) (*pbcore.Record, error) {
+	return s.app.FindFirstRecordByFilter(
+		"sources",
+		"peerId = {:peerId}",
+		dbx.Params{"peerId": strconv.FormatInt(peerID, 10)},
+	)
+}
+
+// refresh copies the chat metadata of seen over source, returning true if
+// anything changed. Metadata missing from an update is kept.
+func refresh(source *core.Source, seen core.Source) bool {
+	changed := false
+	if seen.Type != "" && seen.Type != source.Type {
+		source.Type = seen.Type
+		changed = true
+	}
+	if seen.Title != "" && seen.Title != source.Title {
+		source.Title = seen.Title
+		changed = true
+	}
+	if seen.Username != "" && seen.Username != source.Username {
+		source.Username = seen.Username
+		changed = true
+	}
+	return changed
+}
+
+func toSource(record *pbcore.Record) core.Source {
+	peerID, _ := strconv.ParseInt(record.GetString("peerId"), 10, 64)
+
+	var filter core.SourceFilter
+	_ = record.UnmarshalJSONField("filter", &filter)
+
+	return core.Source{
+		ID:       record.Id,
+		PeerID:   peerID,
+		Type:     core.SourceType(record.GetString("type")),
+		Title:    record.GetString("title"),
+		Username: record.GetString("username"),
+		Enabled:  record.GetBool("enabled"),
+		Pending:  record.GetBool("pending"),
+		Priority: record.GetInt("priority"),
+		Filter:   filter,
+		AddedBy:  record.GetString("addedBy"),
+	}
+}
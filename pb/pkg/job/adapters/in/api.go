@@ -1,28 +1,74 @@
 package in
 
 import (
+	"context"
+	"errors"
+	"sync"
+
 	"svpb-tmpl/pkg/job/core"
+	usagecore "svpb-tmpl/pkg/usage/core"
 
 	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
 	pbcore "github.com/pocketbase/pocketbase/core"
+	"go.uber.org/zap"
 )
 
 // API handles HTTP requests for job module.
 type API struct {
-	service core.JobService
+	service   core.JobService
+	cache     core.ExtractionCache
+	skills    core.SkillService
+	companies core.CompanyService
+	logger    *zap.Logger
+
+	// Background runs are cancelled and waited for on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 // NewAPI creates a new API adapter.
-func NewAPI(service core.JobService) *API {
-	return &API{service: service}
+func NewAPI(
+	service core.JobService,
+	cache core.ExtractionCache,
+	skills core.SkillService,
+	companies core.CompanyService,
+	logger *zap.Logger,
+) *API {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &API{
+		service:   service,
+		cache:     cache,
+		skills:    skills,
+		companies: companies,
+		logger:    logger,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
 }
 
 // Register registers all HTTP routes on the PocketBase app.
 func (a *API) Register(app *pocketbase.PocketBase) {
 	app.OnServe().BindFunc(func(se *pbcore.ServeEvent) error {
 		se.Router.POST("/api/jobs/{id}/generate-offer", a.handleGenerateOffer)
+		se.Router.POST("/api/jobs/{id}/retry", a.handleRetry).Bind(apis.RequireSuperuserAuth())
+		se.Router.POST("/api/jobs/reprocess", a.handleReprocess).Bind(apis.RequireSuperuserAuth())
+		se.Router.GET("/api/jobs/extraction-cache", a.handleCacheStats).Bind(apis.RequireSuperuserAuth())
+		se.Router.GET("/api/jobs/{id}/sightings", a.handleSightings).Bind(apis.RequireAuth())
+		se.Router.GET("/api/jobs/skills/facets", a.handleSkillFacets).Bind(apis.RequireAuth())
+		se.Router.GET("/api/companies/search", a.handleCompanySearch).Bind(apis.RequireAuth())
+		se.Router.GET("/api/companies/{id}/jobs", a.handleCompanyJobs).Bind(apis.RequireAuth())
+		se.Router.GET("/api/companies/{id}/stats", a.handleCompanyStats).Bind(apis.RequireAuth())
 		return se.Next()
 	})
+
+	app.OnTerminate().BindFunc(func(e *pbcore.TerminateEvent) error {
+		a.cancel()
+		a.wg.Wait()
+		return e.Next()
+	})
 }
 
 // handleGenerateOffer generates a personalized offer for a job.
@@ -35,6 +81,12 @@ func (a *API) handleGenerateOffer(e *pbcore.RequestEvent) error {
 	jobID := e.Request.PathValue("id")
 
 	offer, err := a.service.GenerateOffer(e.Request.Context(), jobID, authRecord.Id)
+	if errors.Is(err, usagecore.ErrBudgetExceeded) {
+		return e.TooManyRequestsError("Daily offer generation budget exceeded, try again tomorrow", nil)
+	}
+	if errors.Is(err, core.ErrJobUnavailable) {
+		return e.Error(410, "The vacancy was closed or withdrawn by its poster", nil)
+	}
 	if err != nil {
 		return e.InternalServerError("Failed to generate offer", err)
 	}
@@ -43,3 +95,162 @@ func (a *API) handleGenerateOffer(e *pbcore.RequestEvent) error {
 		"offer": offer,
 	})
 }
+
+// handleRetry moves a failed job back to the processing queue.
+func (a *API) handleRetry(e *pbcore.RequestEvent) error {
+	jobID := e.Request.PathValue("id")
+
+	if err := a.service.Retry(e.Request.Context(), jobID); err != nil {
+		return e.BadRequestError("Failed to retry job", err)
+	}
+
+	return e.NoContent(204)
+}
+
+// reprocessRequest is the body of POST /api/jobs/reprocess.
+type reprocessRequest struct {
+	Statuses    []string `json:"statuses"`
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	ChannelID   string   `json:"channelId"`
+	Filter      string   `json:"filter"`
+	Limit       int      `json:"limit"`
+	Concurrency int      `json:"concurrency"`
+	DryRun      bool     `json:"dryRun"`
+}
+
+// handleReprocess re-extracts matching jobs. Dry runs are answered with the
+// full diff report; real runs continue in the background.
+func (a *API) handleReprocess(e *pbcore.RequestEvent) error {
+	var req reprocessRequest
+	if err := e.BindBody(&req); err != nil {
+		return e.BadRequestError("Invalid request body", err)
+	}
+
+	filter, err := buildReprocessFilter(req.Statuses, req.From, req.To, req.ChannelID, req.Filter, req.Limit)
+	if err != nil {
+		return e.BadRequestError("Invalid filter", err)
+	}
+	if req.DryRun && filter.Limit <= 0 {
+		filter.Limit = core.DryRunLimit
+	}
+
+	ids, err := a.service.FindForReprocess(e.Request.Context(), filter)
+	if err != nil {
+		return e.BadRequestError("Failed to select jobs", err)
+	}
+
+	opts := core.ReprocessOptions{
+		Concurrency: req.Concurrency,
+		DryRun:      req.DryRun,
+	}
+
+	if req.DryRun {
+		report, err := a.service.Reprocess(e.Request.Context(), ids, opts, nil)
+		if errors.Is(err, usagecore.ErrBudgetExceeded) {
+			return e.TooManyRequestsError("Daily extraction budget exceeded, try again tomorrow", nil)
+		}
+		if err != nil {
+			return e.InternalServerError("Failed to reprocess jobs", err)
+		}
+		return e.JSON(200, report)
+	}
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		_, err := a.service.Reprocess(a.ctx, ids, opts, nil)
+		if errors.Is(err, usagecore.ErrBudgetExceeded) {
+			a.logger.Warn("Background reprocess stopped", zap.Error(err))
+		} else if err != nil {
+			a.logger.Error("Background reprocess failed", zap.Error(err))
+		}
+	}()
+
+	return e.JSON(202, map[string]any{
+		"matched": len(ids),
+	})
+}
+
+// handleCacheStats reports extraction cache hits, misses and savings.
+func (a *API) handleCacheStats(e *pbcore.RequestEvent) error {
+	stats, err := a.cache.Stats(e.Request.Context())
+	if err != nil {
+		return e.InternalServerError("Failed to read cache stats", err)
+	}
+
+	return e.JSON(200, stats)
+}
+
+// handleSightings lists the channels and times a job was posted at.
+func (a *API) handleSightings(e *pbcore.RequestEvent) error {
+	sightings, err := a.service.Sightings(e.Request.Context(), e.Request.PathValue("id"))
+	if errors.Is(err, core.ErrJobNotFound) {
+		return e.NotFoundError("Job not found", err)
+	}
+	if err != nil {
+		return e.InternalServerError("Failed to list sightings", err)
+	}
+
+	return e.JSON(200, sightings)
+}
+
+// handleSkillFacets counts processed jobs per canonical skill.
+func (a *API) handleSkillFacets(e *pbcore.RequestEvent) error {
+	facets, err := a.skills.Facets(e.Request.Context())
+	if err != nil {
+		return e.InternalServerError("Failed to count skills", err)
+	}
+
+	return e.JSON(200, facets)
+}
+
+// handleCompanySearch finds companies by name or alias.
+func (a *API) handleCompanySearch(e *pbcore.RequestEvent) error {
+	companies, err := a.companies.Search(e.Request.Context(), e.Request.URL.Query().Get("q"), 20)
+	if err != nil {
+		return e.InternalServerError("Failed to search companies", err)
+	}
+
+	return e.JSON(200, companies)
+}
+
+// handleCompanyJobs lists all postings of a company.
+func (a *API) handleCompanyJobs(e *pbcore.RequestEvent) error {
+	ctx := e.Request.Context()
+	id := e.Request.PathValue("id")
+
+	company, err := a.companies.Get(ctx, id)
+	if err != nil {
+		return e.NotFoundError("Company not found", err)
+	}
+
+	postings, err := a.companies.Postings(ctx, id)
+	if err != nil {
+		return e.InternalServerError("Failed to list postings", err)
+	}
+
+	return e.JSON(200, map[string]any{
+		"company":  company,
+		"postings": postings,
+	})
+}
+
+// handleCompanyStats reports hiring velocity and salary ranges of a company
+// per week or month.
+func (a *API) handleCompanyStats(e *pbcore.RequestEvent) error {
+	interval := e.Request.URL.Query().Get("interval")
+	if interval != "" && interval != core.IntervalWeek && interval != core.IntervalMonth {
+		return e.BadRequestError("interval must be week or month", nil)
+	}
+
+	stats, err := a.companies.Stats(e.Request.Context(), e.Request.PathValue("id"), interval)
+	if errors.Is(err, core.ErrCompanyNotFound) {
+		return e.NotFoundError("Company not found", err)
+	}
+	if err != nil {
+		return e.InternalServerError("Failed to compute company stats", err)
+	}
+
+	return e.JSON(200, stats)
+}
@@ -0,0 +1,476 @@
+package in
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"svpb-tmpl/config"
+	"svpb-tmpl/pkg/job/core"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const dateLayout = "2006-01-02"
+
+// CLI registers job module commands on the PocketBase root command.
+type CLI struct {
+	service   core.JobService
+	skills    core.SkillService
+	companies core.CompanyService
+	rates     core.ExchangeRates
+	salary    config.SalaryConfig
+	logger    *zap.Logger
+}
+
+// NewCLI creates a new CLI adapter.
+func NewCLI(
+	service core.JobService,
+	skills core.SkillService,
+	companies core.CompanyService,
+	rates core.ExchangeRates,
+	salary config.SalaryConfig,
+	logger *zap.Logger,
+) *CLI {
+	return &CLI{
+		service:   service,
+		skills:    skills,
+		companies: companies,
+		rates:     rates,
+		salary:    salary,
+		logger:    logger,
+	}
+}
+
+// Register adds the jobs command group to PocketBase.
+func (c *CLI) Register(app *pocketbase.PocketBase) {
+	jobsCmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Manage collected jobs",
+	}
+
+	jobsCmd.AddCommand(c.reprocessCommand())
+	jobsCmd.AddCommand(c.skillsCommand())
+	jobsCmd.AddCommand(c.companiesCommand())
+	jobsCmd.AddCommand(c.ratesCommand())
+	jobsCmd.AddCommand(c.normalizeSalariesCommand())
+	jobsCmd.AddCommand(c.fingerprintCommand())
+
+	app.RootCmd.AddCommand(jobsCmd)
+}
+
+// reprocessCommand builds `jobs reprocess`.
+func (c *CLI) reprocessCommand() *cobra.Command {
+	var (
+		statuses    []string
+		from, to    string
+		channelID   string
+		expr        string
+		limit       int
+		concurrency int
+		dryRun      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reprocess",
+		Short: "Re-run LLM extraction on existing jobs",
+		Long:  "Selects jobs by status, date range, channel or filter expression, resets them to raw and re-extracts them. Use --dry-run to preview changes without saving.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := buildReprocessFilter(statuses, from, to, channelID, expr, limit)
+			if err != nil {
+				return err
+			}
+			if dryRun && filter.Limit <= 0 {
+				filter.Limit = core.DryRunLimit
+			}
+
+			ctx := cmd.Context()
+
+			ids, err := c.service.FindForReprocess(ctx, filter)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Matched %d jobs\n", len(ids))
+			if len(ids) == 0 {
+				return nil
+			}
+
+			var n atomic.Int32
+			report, err := c.service.Reprocess(ctx, ids, core.ReprocessOptions{
+				Concurrency: concurrency,
+				DryRun:      dryRun,
+			}, func(r core.ReprocessResult) {
+				printReprocessResult(int(n.Add(1)), len(ids), r)
+			})
+
+			fmt.Printf("\nDone: %d, errors: %d, dry run: %t\n", report.Done, report.Errors, report.DryRun)
+			return err
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&statuses, "status", []string{
+		string(core.StatusProcessed),
+		string(core.StatusRejected),
+		string(core.StatusFailed),
+	}, "job statuses to select")
+	cmd.Flags().StringVar(&from, "from", "", "only jobs created on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&to, "to", "", "only jobs created before this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&channelID, "channel", "", "only jobs from this channel id")
+	cmd.Flags().StringVar(&expr, "filter", "", "additional PocketBase filter expression")
+	cmd.Flags().IntVar(&limit, "limit", 0, "max number of jobs (0 = no limit, 20 for dry runs)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 2, "number of parallel extractions")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "extract and show the diff without saving")
+
+	return cmd
+}
+
+// skillsCommand builds `jobs skills list|add|merge|split|relink`.
+func (c *CLI) skillsCommand() *cobra.Command {
+	skillsCmd := &cobra.Command{
+		Use:   "skills",
+		Short: "Manage the canonical skills taxonomy",
+	}
+
+	skillsCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "Show canonical skills and their aliases",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			skills, err := c.skills.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			for _, skill := range skills {
+				fmt.Printf("%-20s %-10s %s\n", skill.Name, skill.Category, strings.Join(skill.Aliases, ", "))
+			}
+			return nil
+		},
+	})
+
+	var addCategory string
+	var addAliases []string
+	addCmd := &cobra.Command{
+		Use:     "add NAME",
+		Short:   "Add a canonical skill or aliases to an existing one",
+		Example: "  jobs skills add Kubernetes --category tool --alias k8s --alias kube",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.skills.Add(cmd.Context(), core.Skill{
+				Name:     args[0],
+				Category: core.SkillCategory(addCategory),
+				Aliases:  addAliases,
+			})
+		},
+	}
+	addCmd.Flags().StringVar(&addCategory, "category", string(core.SkillOther), "language, framework, cloud, db, tool or other")
+	addCmd.Flags().StringSliceVar(&addAliases, "alias", nil, "alternative spelling (repeatable)")
+	skillsCmd.AddCommand(addCmd)
+
+	skillsCmd.AddCommand(&cobra.Command{
+		Use:     "merge TARGET SOURCE [SOURCE...]",
+		Short:   "Fold duplicate skills into TARGET as aliases",
+		Example: "  jobs skills merge Go Golang",
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := c.skills.Merge(cmd.Context(), args[0], args[1:])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Merged into %s, relinked %d jobs\n", args[0], n)
+			return nil
+		},
+	})
+
+	var splitInto, splitCategory string
+	var splitAliases []string
+	splitCmd := &cobra.Command{
+		Use:     "split SOURCE --alias ALIAS... --into NAME",
+		Short:   "Move aliases of SOURCE to another skill",
+		Example: "  jobs skills split Java --alias javascript --into JavaScript --category language",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if splitInto == "" || len(splitAliases) == 0 {
+				return fmt.Errorf("--into and at least one --alias are required")
+			}
+
+			n, err := c.skills.Split(cmd.Context(), args[0], splitAliases, core.Skill{
+				Name:     splitInto,
+				Category: core.SkillCategory(splitCategory),
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Split %s, relinked %d jobs\n", args[0], n)
+			return nil
+		},
+	}
+	splitCmd.Flags().StringSliceVar(&splitAliases, "alias", nil, "alias to move (repeatable)")
+	splitCmd.Flags().StringVar(&splitInto, "into", "", "skill receiving the aliases, created if missing")
+	splitCmd.Flags().StringVar(&splitCategory, "category", string(core.SkillOther), "category of a newly created skill")
+	skillsCmd.AddCommand(splitCmd)
+
+	skillsCmd.AddCommand(&cobra.Command{
+		Use:   "relink",
+		Short: "Re-resolve skills of all processed jobs against the taxonomy",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := c.skills.Relink(cmd.Context())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Relinked %d jobs\n", n)
+			return nil
+		},
+	})
+
+	return skillsCmd
+}
+
+// companiesCommand builds `jobs companies list|add|merge|relink`.
+func (c *CLI) companiesCommand() *cobra.Command {
+	companiesCmd := &cobra.Command{
+		Use:   "companies",
+		Short: "Manage deduplicated companies",
+	}
+
+	companiesCmd.AddCommand(&cobra.Command{
+		Use:   "list [QUERY]",
+		Short: "Show companies, optionally matching QUERY",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := ""
+			if len(args) > 0 {
+				query = args[0]
+			}
+
+			companies, err := c.companies.Search(cmd.Context(), query, 0)
+			if err != nil {
+				return err
+			}
+
+			for _, company := range companies {
+				fmt.Printf("%s  %-30s %s\n", company.ID, company.Name, strings.Join(company.Aliases, ", "))
+			}
+			return nil
+		},
+	})
+
+	var addAliases, addContacts, addWebsites []string
+	addCmd := &cobra.Command{
+		Use:     "add NAME",
+		Short:   "Add a company or aliases, contacts and websites to an existing one",
+		Example: "  jobs companies add Yandex --alias \"Яндекс\" --website yandex.ru --contact @yandex_hr",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.companies.Add(cmd.Context(), core.Company{
+				Name:     args[0],
+				Aliases:  addAliases,
+				Contacts: addContacts,
+				Websites: addWebsites,
+			})
+		},
+	}
+	addCmd.Flags().StringSliceVar(&addAliases, "alias", nil, "alternative name (repeatable)")
+	addCmd.Flags().StringSliceVar(&addContacts, "contact", nil, "Telegram username of a recruiter (repeatable)")
+	addCmd.Flags().StringSliceVar(&addWebsites, "website", nil, "company website (repeatable)")
+	companiesCmd.AddCommand(addCmd)
+
+	companiesCmd.AddCommand(&cobra.Command{
+		Use:     "merge TARGET SOURCE [SOURCE...]",
+		Short:   "Fold duplicate companies into TARGET and move their jobs",
+		Example: "  jobs companies merge Yandex \"Yandex Cloud\"",
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := c.companies.Merge(cmd.Context(), args[0], args[1:])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Merged into %s, moved %d jobs\n", args[0], n)
+			return nil
+		},
+	})
+
+	companiesCmd.AddCommand(&cobra.Command{
+		Use:   "relink",
+		Short: "Link processed jobs without a company",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := c.companies.Relink(cmd.Context())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Linked %d jobs\n", n)
+			return nil
+		},
+	})
+
+	return companiesCmd
+}
+
+// ratesCommand builds `jobs rates list|set`.
+func (c *CLI) ratesCommand() *cobra.Command {
+	ratesCmd := &cobra.Command{
+		Use:   "rates",
+		Short: "Manage exchange rates used for salary normalization",
+	}
+
+	ratesCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "Show exchange rates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rates, err := c.rates.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Base currency: %s\n", c.salary.BaseCurrency)
+			for _, rate := range rates {
+				fmt.Printf("%-4s %14.4f per USD  (updated %s)\n", rate.Currency, rate.PerUSD, rate.Updated.Format(dateLayout))
+			}
+			return nil
+		},
+	})
+
+	var skipNormalize bool
+	setCmd := &cobra.Command{
+		Use:     "set CURRENCY PER_USD [CURRENCY PER_USD...]",
+		Short:   "Set the price of one US dollar in a currency",
+		Example: "  jobs rates set EUR 0.86 RUB 80.5",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 || len(args)%2 != 0 {
+				return fmt.Errorf("expected CURRENCY PER_USD pairs")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			var currencies []string
+			for i := 0; i < len(args); i += 2 {
+				perUSD, err := strconv.ParseFloat(args[i+1], 64)
+				if err != nil {
+					return fmt.Errorf("invalid rate for %s: %w", args[i], err)
+				}
+				if err := c.rates.Set(ctx, args[i], perUSD); err != nil {
+					return err
+				}
+				currencies = append(currencies, core.NormalizeCurrency(args[i]))
+			}
+
+			if skipNormalize {
+				return nil
+			}
+
+			for _, currency := range currencies {
+				// The base rate affects every conversion
+				if currency == c.salary.BaseCurrency || currency == "USD" {
+					currencies = []string{""}
+					break
+				}
+			}
+
+			for _, currency := range currencies {
+				n, err := c.service.NormalizeSalaries(ctx, currency)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Renormalized %d jobs\n", n)
+			}
+			return nil
+		},
+	}
+	setCmd.Flags().BoolVar(&skipNormalize, "no-normalize", false, "do not recompute salaries of existing jobs")
+	ratesCmd.AddCommand(setCmd)
+
+	return ratesCmd
+}
+
+// normalizeSalariesCommand builds `jobs normalize-salaries`.
+func (c *CLI) normalizeSalariesCommand() *cobra.Command {
+	var currency string
+
+	cmd := &cobra.Command{
+		Use:   "normalize-salaries",
+		Short: "Recompute monthly base currency salaries of processed jobs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := c.service.NormalizeSalaries(cmd.Context(), currency)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Normalized %d jobs to %s/month\n", n, c.salary.BaseCurrency)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&currency, "currency", "", "only jobs with this salary currency")
+
+	return cmd
+}
+
+// fingerprintCommand builds `jobs fingerprint`.
+func (c *CLI) fingerprintCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "fingerprint",
+		Short: "Compute near-duplicate fingerprints of jobs collected without one",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := c.service.Fingerprint(cmd.Context())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Fingerprinted %d jobs\n", n)
+			return nil
+		},
+	}
+}
+
+// buildReprocessFilter converts raw flag/request values into a core.ReprocessFilter.
+func buildReprocessFilter(statuses []string, from, to, channelID, expr string, limit int) (core.ReprocessFilter, error) {
+	filter := core.ReprocessFilter{
+		ChannelID: channelID,
+		Expr:      expr,
+		Limit:     limit,
+	}
+
+	for _, status := range statuses {
+		if status = strings.TrimSpace(status); status != "" {
+			filter.Statuses = append(filter.Statuses, core.JobStatus(status))
+		}
+	}
+
+	var err error
+	if from != "" {
+		if filter.From, err = time.Parse(dateLayout, from); err != nil {
+			return filter, fmt.Errorf("invalid from date: %w", err)
+		}
+	}
+	if to != "" {
+		if filter.To, err = time.Parse(dateLayout, to); err != nil {
+			return filter, fmt.Errorf("invalid to date: %w", err)
+		}
+	}
+
+	return filter, nil
+}
+
+// printReprocessResult writes a progress line and the ParsedData diff.
+func printReprocessResult(n, total int, r core.ReprocessResult) {
+	if r.Error != "" {
+		fmt.Printf("[%d/%d] %s error: %s\n", n, total, r.JobID, r.Error)
+		return
+	}
+
+	fmt.Printf("[%d/%d] %s %s (%d changes)\n", n, total, r.JobID, r.Status, len(r.Changes))
+	for _, change := range r.Changes {
+		fmt.Printf("    %s: %s -> %s\n", change.Field, formatValue(change.Old), formatValue(change.New))
+	}
+}
+
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -12,25 +12,40 @@ import (
 
 // Hooks handles PocketBase lifecycle events for job module.
 type Hooks struct {
-	service core.JobService
-	logger  *zap.Logger
+	queue  core.JobQueue
+	cache  core.ExtractionCache
+	skills core.SkillService
+	logger *zap.Logger
 }
 
 // NewHooks creates a new Hooks adapter.
-func NewHooks(service core.JobService, logger *zap.Logger) *Hooks {
+func NewHooks(queue core.JobQueue, cache core.ExtractionCache, skills core.SkillService, logger *zap.Logger) *Hooks {
 	return &Hooks{
-		service: service,
-		logger:  logger,
+		queue:  queue,
+		cache:  cache,
+		skills: skills,
+		logger: logger,
 	}
 }
 
 // Register registers all PocketBase hooks.
 func (h *Hooks) Register(app *pocketbase.PocketBase) {
-	// Process raw jobs after creation
+	// Queue raw jobs for processing after creation
 	app.OnRecordAfterCreateSuccess("jobs").BindFunc(h.onJobCreated)
+
+	// Re-queue jobs moved back to raw (retries, admin edits)
+	app.OnRecordAfterUpdateSuccess("jobs").BindFunc(h.onJobUpdated)
+
+	// Reload the taxonomy after skills are edited, e.g. in the admin UI
+	app.OnRecordAfterCreateSuccess("skills").BindFunc(h.onSkillChanged)
+	app.OnRecordAfterUpdateSuccess("skills").BindFunc(h.onSkillChanged)
+	app.OnRecordAfterDeleteSuccess("skills").BindFunc(h.onSkillChanged)
+
+	// Drop expired extraction cache entries nightly
+	app.Cron().MustAdd("purgeExtractionCache", "30 3 * * *", h.purgeExtractionCache)
 }
 
-// onJobCreated triggers LLM processing when a raw job is created.
+// onJobCreated enqueues a raw job for LLM processing by the worker pool.
 func (h *Hooks) onJobCreated(e *pbcore.RecordEvent) error {
 	record := e.Record
 
@@ -39,15 +54,50 @@ func (h *Hooks) onJobCreated(e *pbcore.RecordEvent) error {
 		return e.Next()
 	}
 
-	// Run processing in background to not block the request
-	go func() {
-		if err := h.service.Process(context.Background(), record.Id); err != nil {
-			h.logger.Error("Job processing failed",
-				zap.Error(err),
-				zap.String("jobId", record.Id),
-			)
-		}
-	}()
+	if err := h.queue.Enqueue(e.Context, record.Id); err != nil {
+		// The startup sweep picks up raw jobs that failed to enqueue
+		h.logger.Error("Failed to enqueue job",
+			zap.Error(err),
+			zap.String("jobId", record.Id),
+		)
+	}
 
 	return e.Next()
 }
+
+// onJobUpdated enqueues a job that transitioned back to raw.
+func (h *Hooks) onJobUpdated(e *pbcore.RecordEvent) error {
+	record := e.Record
+
+	if record.GetString("status") != string(core.StatusRaw) ||
+		record.Original().GetString("status") == string(core.StatusRaw) {
+		return e.Next()
+	}
+
+	if err := h.queue.Enqueue(e.Context, record.Id); err != nil {
+		h.logger.Error("Failed to re-enqueue job",
+			zap.Error(err),
+			zap.String("jobId", record.Id),
+		)
+	}
+
+	return e.Next()
+}
+
+// onSkillChanged drops the cached skills taxonomy. Existing jobs keep their
+// skills until `jobs skills relink`.
+func (h *Hooks) onSkillChanged(e *pbcore.RecordEvent) error {
+	h.skills.Invalidate()
+	return e.Next()
+}
+
+// purgeExtractionCache removes expired extraction cache entries.
+func (h *Hooks) purgeExtractionCache() {
+	n, err := h.cache.Purge(context.Background())
+	if err != nil {
+		h.logger.Error("Failed to purge extraction cache", zap.Error(err))
+		return
+	}
+
+	h.logger.Info("Extraction cache purged", zap.Int("removed", n))
+}
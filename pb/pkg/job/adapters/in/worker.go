@@ -0,0 +1,201 @@
+package in
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"svpb-tmpl/config"
+	"svpb-tmpl/pkg/job/core"
+	usagecore "svpb-tmpl/pkg/usage/core"
+
+	"github.com/pocketbase/pocketbase"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"go.uber.org/zap"
+)
+
+// budgetRecheckInterval is how often paused workers check whether the
+// extraction budget allows leasing again.
+const budgetRecheckInterval = time.Minute
+
+// Worker drains the job queue with a bounded pool of goroutines.
+type Worker struct {
+	cfg     config.QueueConfig
+	queue   core.JobQueue
+	service core.JobService
+	usage   usagecore.UsageService
+	logger  *zap.Logger
+
+	paused         atomic.Bool
+	wg             sync.WaitGroup
+	stopLeasing    context.CancelFunc
+	cancelInFlight context.CancelFunc
+}
+
+// NewWorker creates a new queue Worker.
+func NewWorker(
+	cfg config.QueueConfig,
+	queue core.JobQueue,
+	service core.JobService,
+	usage usagecore.UsageService,
+	logger *zap.Logger,
+) *Worker {
+	return &Worker{
+		cfg:     cfg,
+		queue:   queue,
+		service: service,
+		usage:   usage,
+		logger:  logger,
+	}
+}
+
+// Register starts the pool when the server starts and drains it on shutdown.
+func (w *Worker) Register(app *pocketbase.PocketBase) {
+	app.OnServe().BindFunc(func(se *pbcore.ServeEvent) error {
+		w.Start()
+		return se.Next()
+	})
+
+	app.OnTerminate().BindFunc(func(e *pbcore.TerminateEvent) error {
+		w.Stop()
+		return e.Next()
+	})
+}
+
+// Start re-queues unfinished jobs and launches the worker goroutines.
+func (w *Worker) Start() {
+	leaseCtx, stopLeasing := context.WithCancel(context.Background())
+	processCtx, cancelInFlight := context.WithCancel(context.Background())
+	w.stopLeasing = stopLeasing
+	w.cancelInFlight = cancelInFlight
+
+	requeued, err := w.queue.Sweep(leaseCtx)
+	if err != nil {
+		w.logger.Error("Startup queue sweep failed", zap.Error(err))
+	} else if requeued > 0 {
+		w.logger.Info("Re-queued unfinished jobs", zap.Int("count", requeued))
+	}
+
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		w.wg.Add(1)
+		go w.run(leaseCtx, processCtx)
+	}
+
+	w.logger.Info("Job queue workers started", zap.Int("concurrency", w.cfg.Concurrency))
+}
+
+// Stop stops leasing new entries and waits for in-flight jobs up to the
+// shutdown grace period. Jobs still running after that are cancelled and
+// their entries are retried or re-leased on the next start.
+func (w *Worker) Stop() {
+	if w.stopLeasing == nil {
+		return
+	}
+	w.stopLeasing()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(w.cfg.ShutdownGrace):
+		w.logger.Warn("Shutdown grace period elapsed, cancelling in-flight jobs")
+		w.cancelInFlight()
+		<-done
+	}
+
+	w.cancelInFlight()
+	w.logger.Info("Job queue workers stopped")
+}
+
+// run leases and processes entries until leaseCtx is cancelled.
+func (w *Worker) run(leaseCtx, processCtx context.Context) {
+	defer w.wg.Done()
+
+	for {
+		if leaseCtx.Err() != nil {
+			return
+		}
+
+		// Jobs stay raw in the queue until the budget resets
+		if !w.budgetAllows(leaseCtx) {
+			select {
+			case <-leaseCtx.Done():
+				return
+			case <-time.After(budgetRecheckInterval):
+			}
+			continue
+		}
+
+		item, err := w.queue.Lease(leaseCtx)
+		if err != nil {
+			w.logger.Error("Failed to lease job", zap.Error(err))
+		}
+
+		if item == nil {
+			select {
+			case <-leaseCtx.Done():
+				return
+			case <-time.After(w.cfg.PollInterval):
+			}
+			continue
+		}
+
+		w.process(processCtx, *item)
+	}
+}
+
+// budgetAllows reports whether extraction may spend more today and logs
+// pause/resume transitions once for the whole pool.
+func (w *Worker) budgetAllows(ctx context.Context) bool {
+	err := w.usage.CheckBudget(ctx, usagecore.StageExtraction)
+	if errors.Is(err, usagecore.ErrBudgetExceeded) {
+		if !w.paused.Swap(true) {
+			w.logger.Warn("Extraction paused", zap.Error(err))
+		}
+		return false
+	}
+
+	if err != nil {
+		// Do not stall the queue because usage could not be read
+		w.logger.Error("Failed to check extraction budget", zap.Error(err))
+	}
+
+	if w.paused.Swap(false) {
+		w.logger.Info("Extraction resumed")
+	}
+	return true
+}
+
+// process runs a single leased job and reports the outcome to the queue.
+func (w *Worker) process(processCtx context.Context, item core.QueueItem) {
+	ctx, cancel := context.WithTimeout(processCtx, w.cfg.LeaseTimeout)
+	defer cancel()
+
+	err := w.service.Process(ctx, item.JobID)
+	if errors.Is(err, core.ErrNotProcessable) {
+		// Closed, withdrawn or processed since it was queued: nothing to retry
+		w.logger.Info("Skipping job", zap.Error(err), zap.String("jobId", item.JobID))
+		err = nil
+	}
+	if err != nil {
+		w.logger.Error("Job processing failed",
+			zap.Error(err),
+			zap.String("jobId", item.JobID),
+			zap.Int("attempt", item.Attempt),
+		)
+		if nackErr := w.queue.Nack(context.Background(), item, err); nackErr != nil {
+			w.logger.Error("Failed to nack job", zap.Error(nackErr), zap.String("jobId", item.JobID))
+		}
+		return
+	}
+
+	if err := w.queue.Ack(context.Background(), item); err != nil {
+		w.logger.Error("Failed to ack job", zap.Error(err), zap.String("jobId", item.JobID))
+	}
+}
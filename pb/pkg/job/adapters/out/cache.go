@@ -0,0 +1,240 @@
+package out
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"svpb-tmpl/pkg/job/core"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"go.uber.org/zap"
+)
+
+const extractionCacheCollection = "extractionCache"
+
+// CachedExtractor implements core.ExtractionCache. Results are keyed by
+// (normalized text hash, model that answered, prompt version) and stored in
+// the extractionCache collection until they expire.
+type CachedExtractor struct {
+	app    *pocketbase.PocketBase
+	inner  core.JobExtractor
+	models []string
+	prompt string
+	ttl    time.Duration
+	logger *zap.Logger
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCachedExtractor wraps inner with a persistent cache.
+// models is the "provider:model" chain inner runs: entries of models no longer
+// in it are ignored, the others are preferred in chain order. Changing prompt
+// invalidates previous entries.
+func NewCachedExtractor(
+	app *pocketbase.PocketBase,
+	inner core.JobExtractor,
+	models []string,
+	prompt string,
+	ttl time.Duration,
+	logger *zap.Logger,
+) *CachedExtractor {
+	return &CachedExtractor{
+		app:    app,
+		inner:  inner,
+		models: models,
+		prompt: prompt,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// Extract returns a cached extraction for identical text or delegates to
+// the wrapped extractor and stores its result. Bypassing calls leave the
+// cache untouched.
+func (c *CachedExtractor) Extract(ctx context.Context, text string) (core.Extraction, error) {
+	textHash := normalizedHash(text)
+
+	if !core.CacheSkipped(ctx) {
+		if extraction, ok := c.lookup(textHash); ok {
+			c.hits.Add(1)
+			return extraction, nil
+		}
+		c.misses.Add(1)
+	}
+
+	extraction, err := c.inner.Extract(ctx, text)
+	if err != nil || core.CacheBypassed(ctx) {
+		return extraction, err
+	}
+
+	if err := c.store(textHash, extraction); err != nil {
+		c.logger.Warn("Failed to store extraction in cache", zap.Error(err))
+	}
+
+	return extraction, nil
+}
+
+// Stats returns hit/miss counters and estimated savings.
+func (c *CachedExtractor) Stats(ctx context.Context) (core.ExtractionCacheStats, error) {
+	stats := core.ExtractionCacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+
+	var row struct {
+		Entries     int `db:"entries"`
+		TotalHits   int `db:"totalHits"`
+		TokensSaved int `db:"tokensSaved"`
+	}
+
+	err := c.app.DB().
+		Select(
+			"COUNT(*) AS entries",
+			"COALESCE(SUM(hits), 0) AS totalHits",
+			"COALESCE(SUM(hits * tokens), 0) AS tokensSaved",
+		).
+		From(extractionCacheCollection).
+		One(&row)
+	if err != nil {
+		return stats, fmt.Errorf("failed to aggregate cache stats: %w", err)
+	}
+
+	stats.Entries = row.Entries
+	stats.TotalHits = row.TotalHits
+	stats.TokensSaved = row.TokensSaved
+
+	return stats, nil
+}
+
+// Purge removes expired entries.
+func (c *CachedExtractor) Purge(ctx context.Context) (int, error) {
+	result, err := c.app.DB().Delete(
+		extractionCacheCollection,
+		dbx.NewExp("expiresAt <= {:now}", dbx.Params{"now": types.NowDateTime().String()}),
+	).Execute()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge extraction cache: %w", err)
+	}
+
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// lookup returns a non-expired cached extraction of a model in the chain,
+// the earliest in the chain first, and bumps its hit counter.
+func (c *CachedExtractor) lookup(textHash string) (core.Extraction, bool) {
+	records, err := c.app.FindRecordsByFilter(
+		extractionCacheCollection,
+		"textHash = {:textHash} && prompt = {:prompt} && expiresAt > {:now}",
+		"",
+		0,
+		0,
+		dbx.Params{
+			"textHash": textHash,
+			"prompt":   c.prompt,
+			"now":      types.NowDateTime().String(),
+		},
+	)
+	if err != nil {
+		return core.Extraction{}, false
+	}
+
+	var record *pbcore.Record
+	for _, spec := range c.models {
+		i := slices.IndexFunc(records, func(r *pbcore.Record) bool {
+			return answeredBy(r.GetString("model"), spec)
+		})
+		if i >= 0 {
+			record = records[i]
+			break
+		}
+	}
+	if record == nil {
+		return core.Extraction{}, false
+	}
+
+	var extraction core.Extraction
+	if err := record.UnmarshalJSONField("data", &extraction.Data); err != nil {
+		return core.Extraction{}, false
+	}
+	if err := record.UnmarshalJSONField("provenance", &extraction.Provenance); err != nil {
+		return core.Extraction{}, false
+	}
+	_ = record.UnmarshalJSONField("siblings", &extraction.Siblings)
+
+	// The fields still come from the original model/prompt, but this call
+	// consumed no tokens.
+	extraction.Provenance.Extractor = "cache/" + extraction.Provenance.Extractor
+	extraction.Provenance.PromptTokens = 0
+	extraction.Provenance.CompletionTokens = 0
+
+	record.Set("hits", record.GetInt("hits")+1)
+	if err := c.app.Save(record); err != nil {
+		c.logger.Warn("Failed to update cache hits", zap.Error(err))
+	}
+
+	return extraction, true
+}
+
+// store upserts the cache entry of the model that produced extraction.
+func (c *CachedExtractor) store(textHash string, extraction core.Extraction) error {
+	model := extraction.Provenance.Extractor + ":" + extraction.Provenance.Model
+	key := c.key(textHash, model)
+
+	record, err := c.app.FindFirstRecordByFilter(extractionCacheCollection, "cacheKey = {:key}", dbx.Params{"key": key})
+	if err != nil {
+		collection, err := c.app.FindCollectionByNameOrId(extractionCacheCollection)
+		if err != nil {
+			return err
+		}
+		record = pbcore.NewRecord(collection)
+		record.Set("cacheKey", key)
+	}
+
+	record.Set("textHash", textHash)
+	record.Set("model", model)
+	record.Set("prompt", c.prompt)
+	record.Set("data", extraction.Data)
+	record.Set("siblings", extraction.Siblings)
+	record.Set("provenance", extraction.Provenance)
+	record.Set("tokens", extraction.Provenance.PromptTokens+extraction.Provenance.CompletionTokens)
+	record.Set("expiresAt", time.Now().Add(c.ttl))
+
+	return c.app.Save(record)
+}
+
+// key combines the text hash with the extraction setup.
+func (c *CachedExtractor) key(textHash, model string) string {
+	sum := sha256.Sum256([]byte(textHash + "|" + model + "|" + c.prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// answeredBy reports whether model, as recorded from a response, is served
+// by the chain spec. APIs may answer with a dated snapshot of the requested
+// model, e.g. "openai:gpt-5-nano-2025-08-07" for "openai:gpt-5-nano".
+func answeredBy(model, spec string) bool {
+	spec = strings.TrimSpace(spec)
+	snapshot, ok := strings.CutPrefix(model, spec+"-")
+	if !ok {
+		return model == spec
+	}
+	return strings.Trim(snapshot, "0123456789-") == ""
+}
+
+// normalizedHash hashes text ignoring case and whitespace, like the
+// collector does for exact deduplication.
+func normalizedHash(text string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,26 @@
+package out
+
+import "testing"
+
+func TestAnsweredBy(t *testing.T) {
+	tests := []struct {
+		model, spec string
+		want        bool
+	}{
+		{"openai:gpt-5-nano", "openai:gpt-5-nano", true},
+		{"openai:gpt-5-nano-2025-08-07", "openai:gpt-5-nano", true},
+		{"openai:gpt-5-nano-2025-08-07", " openai:gpt-5-nano ", true},
+		{"ollama:qwen2.5:7b", "ollama:qwen2.5:7b", true},
+		{"anthropic:claude-sonnet-4-5-20250929", "anthropic:claude-sonnet-4-5", true},
+		{"openai:gpt-5-nano", "openai:gpt-5", false},
+		{"openai:gpt-5-nano-2025-08-07", "openai:gpt-5", false},
+		{"openai:gpt-5-nano", "openai:gpt-5-nano-2025-08-07", false},
+		{"ollama:gpt-5-nano", "openai:gpt-5-nano", false},
+	}
+
+	for _, tt := range tests {
+		if got := answeredBy(tt.model, tt.spec); got != tt.want {
+			t.Errorf("answeredBy(%q, %q) = %v, want %v", tt.model, tt.spec, got, tt.want)
+		}
+	}
+}
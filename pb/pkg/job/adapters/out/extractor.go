@@ -2,78 +2,134 @@ package out
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"time"
 
+	"svpb-tmpl/infra/llm"
 	"svpb-tmpl/pkg/job/core"
 
-	openai "github.com/sashabaranov/go-openai"
 	"github.com/sashabaranov/go-openai/jsonschema"
 )
 
+// extractionPromptVersion must be bumped whenever extractionPrompt or the
+// ParsedData schema changes in a way that affects extraction results.
+const extractionPromptVersion = "v4"
+
 const extractionPrompt = `You are a job vacancy parser. Your task is to analyze text messages and extract structured data about job postings.
 
 IMPORTANT RULES:
-1. If the text is NOT a job vacancy (e.g., advertisement, news, chat message), set isVacancy to false and leave other fields empty/default.
-2. If it IS a vacancy, set isVacancy to true and EXTRACT the job title (e.g., "Golang Developer", "Product Manager").
+1. If the text is NOT a job vacancy (e.g., advertisement, news, chat message), return a single item in vacancies with isVacancy false and other fields empty/default.
+2. If it IS a vacancy, set isVacancy to true and EXTRACT the job title (e.g., "Golang Developer", "Product Manager"). If the text lists several different positions (e.g. "5 openings at X"), return one item per position, repeating shared details (company, location, contacts) in each.
 3. If the title is not explicitly stated, infer it from the context or use the most prominent role mentioned. NEVER leave title empty if isVacancy is true.
-4. Extract salary information if present. Convert to numbers only, no currency symbols.
-5. Identify the currency from context (look for $, €, ₽, USD, EUR, RUB, etc.)
+4. Extract salary information if present. Convert to numbers only, no currency symbols ("150k" -> 150000).
+5. Identify the currency from context (look for $, €, ₽, USD, EUR, RUB, etc.) and return its ISO 4217 code (USD, EUR, RUB, ...).
 6. Extract required skills/technologies as a list of short keywords.
 7. Determine job grade from context clues (Junior/Middle/Senior/Lead/Principal).
 8. Set isRemote to true if remote work, WFH, or distributed team is mentioned.
+9. Set salaryPeriod to "hour", "month" or "year" when the salary is quoted per hour ("/hr", "в час"), per month ("/mo", "в месяц") or per year ("/year", "в год", "per annum"). Leave it empty if not stated.
+10. Set salaryTax to "gross" (before taxes, "gross", "до вычета") or "net" (after taxes, "net", "на руки", "чистыми"). Leave it empty if not stated.
+11. Extract every way to apply into contacts: Telegram usernames ("@hr_anna", "t.me/hr_anna" -> kind "telegram", value "hr_anna"), emails, phone numbers, application forms (Google Forms, Typeform, ...) and apply URLs. Do not include links to the channel or the post itself.
 
 Always respond with valid JSON matching the schema exactly.`
 
+// Extractor implements core.JobExtractor using an LLM chain.
 type Extractor struct {
-	client *openai.Client
-	model  string
+	llm        llm.Completer
+	schema     json.RawMessage
+	promptHash string
 }
 
-func NewExtractor(client *openai.Client) *Extractor {
+// NewExtractor creates a new extractor backed by the given completer.
+func NewExtractor(completer llm.Completer) *Extractor {
+	schema, err := json.Marshal(parsedPostSchema())
+	if err != nil {
+		panic(err)
+	}
+
 	return &Extractor{
-		client: client,
-		model:  "gpt-5-nano",
+		llm:        completer,
+		schema:     schema,
+		promptHash: promptHash(schema),
 	}
 }
 
-func (e *Extractor) Extract(ctx context.Context, text string) (core.ParsedData, error) {
-	resp, err := e.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: e.model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: extractionPrompt,
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: text,
-				},
-			},
-			ResponseFormat: &openai.ChatCompletionResponseFormat{
-				Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
-				JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
-					Name:   "job_parser",
-					Schema: parsedDataSchema(),
-					Strict: true,
-				},
-			},
-		},
-	)
+// Extract parses job posting text into ParsedData.
+// Implements core.JobExtractor interface.
+func (e *Extractor) Extract(ctx context.Context, text string) (core.Extraction, error) {
+	req := llm.UserPrompt(extractionPrompt, text)
+	req.SchemaName = "job_parser"
+	req.Schema = e.schema
 
+	resp, err := e.llm.Complete(ctx, req)
 	if err != nil {
-		return core.ParsedData{}, err
+		return core.Extraction{}, err
 	}
 
-	var result core.ParsedData
-	err = json.Unmarshal([]byte(resp.Choices[0].Message.Content), &result)
-	return result, err
+	var result parsedPost
+	if err := json.Unmarshal([]byte(resp.Content), &result); err != nil {
+		return core.Extraction{}, fmt.Errorf("invalid %s/%s output: %w", resp.Provider, resp.Model, err)
+	}
+
+	data, siblings := result.split()
+
+	return core.Extraction{
+		Data:     data,
+		Siblings: siblings,
+		Provenance: core.Provenance{
+			Extractor:        resp.Provider,
+			Model:            resp.Model,
+			PromptVersion:    extractionPromptVersion,
+			PromptHash:       e.promptHash,
+			PromptTokens:     resp.PromptTokens,
+			CompletionTokens: resp.CompletionTokens,
+			ExtractedAt:      time.Now(),
+		},
+	}, nil
+}
+
+// PromptVersion identifies the prompt and schema, e.g. for cache keys.
+func (e *Extractor) PromptVersion() string {
+	return extractionPromptVersion + "+" + e.promptHash
+}
+
+// promptHash fingerprints the prompt and schema so silent edits that forget
+// to bump extractionPromptVersion are still distinguishable.
+func promptHash(schema []byte) string {
+	sum := sha256.Sum256([]byte(extractionPrompt + string(schema)))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// parsedPost is the LLM output: one item per vacancy in the post.
+type parsedPost struct {
+	Vacancies []core.ParsedData `json:"vacancies"`
+}
+
+// split returns the first vacancy and the rest. Non-vacancy items are dropped
+// unless there is no vacancy at all.
+func (p parsedPost) split() (core.ParsedData, []core.ParsedData) {
+	var vacancies []core.ParsedData
+	for _, v := range p.Vacancies {
+		if v.IsVacancy {
+			vacancies = append(vacancies, v)
+		}
+	}
+
+	switch {
+	case len(vacancies) > 0:
+		return vacancies[0], vacancies[1:]
+	case len(p.Vacancies) > 0:
+		return p.Vacancies[0], nil
+	default:
+		return core.ParsedData{}, nil
+	}
 }
 
-// parsedDataSchema returns JSON schema for ParsedData.
-func parsedDataSchema() *jsonschema.Definition {
-	schema, err := jsonschema.GenerateSchemaForType(core.ParsedData{})
+// parsedPostSchema returns JSON schema for the LLM output.
+func parsedPostSchema() *jsonschema.Definition {
+	schema, err := jsonschema.GenerateSchemaForType(parsedPost{})
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,53 @@
+package out
+
+import (
+	"context"
+
+	"svpb-tmpl/pkg/job/core"
+
+	"go.uber.org/zap"
+)
+
+// FallbackExtractor implements core.JobExtractor on top of an LLM extractor
+// and a deterministic one. The deterministic result is computed first as a
+// pre-fill: LLM output is sanity checked against it, and it is returned as is
+// when the LLM fails.
+type FallbackExtractor struct {
+	primary  core.JobExtractor
+	prefill  core.JobExtractor
+	fallback bool
+	logger   *zap.Logger
+}
+
+// NewFallbackExtractor creates a new extractor. With fallback disabled the
+// pre-fill is only used for sanity checks and LLM errors are returned.
+func NewFallbackExtractor(primary, prefill core.JobExtractor, fallback bool, logger *zap.Logger) *FallbackExtractor {
+	return &FallbackExtractor{
+		primary:  primary,
+		prefill:  prefill,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
+
+// Extract implements core.JobExtractor.
+func (f *FallbackExtractor) Extract(ctx context.Context, text string) (core.Extraction, error) {
+	prefill, prefillErr := f.prefill.Extract(ctx, text)
+
+	extraction, err := f.primary.Extract(ctx, text)
+	if err != nil {
+		// Shutdown or timeout, let the queue retry with the LLM later
+		if ctx.Err() != nil || !f.fallback || prefillErr != nil {
+			return extraction, err
+		}
+
+		f.logger.Warn("LLM extraction failed, using rule-based fallback", zap.Error(err))
+		return prefill, nil
+	}
+
+	if prefillErr == nil {
+		extraction.Warnings = core.SanityCheck(extraction.Data, prefill.Data)
+	}
+
+	return extraction, nil
+}
@@ -3,7 +3,7 @@ package out
 import (
 	"context"
 
-	openai "github.com/sashabaranov/go-openai"
+	"svpb-tmpl/infra/llm"
 )
 
 const offerSystemPrompt = `
@@ -51,47 +51,28 @@ OUTPUT FORMAT:
 Return ONLY the raw message text.
 `
 
-// OfferGenerator implements core.OfferGenerator using OpenAI.
+// OfferGenerator implements core.OfferGenerator using an LLM chain.
 type OfferGenerator struct {
-	client *openai.Client
-	model  string
+	llm llm.Completer
 }
 
-// NewOfferGenerator creates a new offer generator with the given OpenAI client.
-func NewOfferGenerator(client *openai.Client) *OfferGenerator {
+// NewOfferGenerator creates a new offer generator backed by the given completer.
+func NewOfferGenerator(completer llm.Completer) *OfferGenerator {
 	return &OfferGenerator{
-		client: client,
-		model:  "gpt-5.2",
+		llm: completer,
 	}
 }
 
 // Generate creates a personalized first touch message.
 // Implements core.OfferGenerator interface.
 func (g *OfferGenerator) Generate(ctx context.Context, cv, jobDescription string) (string, error) {
-	resp, err := g.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: g.model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: offerSystemPrompt,
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: "CV: " + cv + "\n\nJob Description: " + jobDescription,
-				},
-			},
-		},
-	)
-
+	resp, err := g.llm.Complete(ctx, llm.UserPrompt(
+		offerSystemPrompt,
+		"CV: "+cv+"\n\nJob Description: "+jobDescription,
+	))
 	if err != nil {
 		return "", err
 	}
 
-	if len(resp.Choices) == 0 {
-		return "", nil
-	}
-
-	return resp.Choices[0].Message.Content, nil
+	return resp.Content, nil
 }
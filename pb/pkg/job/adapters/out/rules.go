@@ -0,0 +1,416 @@
+package out
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"svpb-tmpl/pkg/job/core"
+)
+
+// rulesVersion must be bumped whenever the rules or dictionaries change.
+const rulesVersion = "rules-v3"
+
+const maxTitleLength = 100
+
+var (
+	salaryNumber = `(\d{1,3}(?:[ \x{00A0}.,]\d{3})+|\d+(?:[.,]\d+)?)\s*(k|к|тыс\.?|тысяч)?`
+	salaryCur    = `([$€₽£]|usd|eur|rub|gbp|руб(?:\.|лей|ля)?|р\.|долл(?:\.|аров)?|евро)`
+
+	// e.g. "от 3000$", "200-250к ₽", "$5k/mo", "от 150 000 до 200 000 руб"
+	salaryPattern = regexp.MustCompile(`(?i)(?:(?:^|[^\p{L}])(от|from|до|up to)\s*)?` + salaryCur + `?\s*` + salaryNumber +
+		`(?:\s*(?:-|–|—|до|to)\s*` + salaryCur + `?\s*` + salaryNumber + `)?\s*` + salaryCur + `?`)
+
+	salaryKeywords = []string{"зп", "з/п", "зарплата", "оклад", "вилка", "доход", "salary", "compensation", "pay"}
+
+	currencyCodes = map[string]string{
+		"$": "USD", "usd": "USD", "долл": "USD", "доллар": "USD",
+		"€": "EUR", "eur": "EUR", "евро": "EUR",
+		"₽": "RUB", "rub": "RUB", "руб": "RUB", "р": "RUB",
+		"£": "GBP", "gbp": "GBP",
+	}
+
+	vacancyKeywords = []string{
+		"вакансия", "ищем", "требуется", "в команду", "открыта позиция",
+		"vacancy", "hiring", "we are looking", "looking for", "join our team", "open position",
+	}
+
+	remoteKeywords = []string{
+		"remote", "удален", "удалён", "wfh", "work from home", "distributed team", "из любой точки",
+	}
+
+	// Go's \b is ASCII-only, so boundaries are spelled out to work with Cyrillic
+	gradePatterns = []struct {
+		grade string
+		re    *regexp.Regexp
+	}{
+		{"Junior", regexp.MustCompile(`(?i)(?:^|[^\p{L}])(?:junior|джун|младш)`)},
+		{"Middle", regexp.MustCompile(`(?i)(?:^|[^\p{L}])(?:middle|мидл)`)},
+		{"Senior", regexp.MustCompile(`(?i)(?:^|[^\p{L}])(?:senior|сеньор|синьор|старш)`)},
+		{"Lead", regexp.MustCompile(`(?i)(?:^|[^\p{L}])(?:team ?lead|tech ?lead|lead|тимлид|техлид|лид)(?:$|[^\p{L}])`)},
+		{"Principal", regexp.MustCompile(`(?i)(?:^|[^\p{L}])(?:principal|staff engineer|architect|архитектор)`)},
+	}
+
+	// Checked in order, hour and year markers are more specific than month ones
+	periodPatterns = []struct {
+		period core.SalaryPeriod
+		re     *regexp.Regexp
+	}{
+		{core.PeriodHour, regexp.MustCompile(`(?i)(/\s*(?:h|hr|hour|ч|час)(?:$|[^\p{L}])|в час|per hour|hourly|почасов)`)},
+		{core.PeriodYear, regexp.MustCompile(`(?i)(/\s*(?:y|yr|year|год)(?:$|[^\p{L}])|в год|per year|per annum|annual|годов)`)},
+		{core.PeriodMonth, regexp.MustCompile(`(?i)(/\s*(?:mo|month|мес)|в месяц|per month|monthly|ежемесячн)`)},
+	}
+
+	taxPatterns = []struct {
+		tax core.SalaryTax
+		re  *regexp.Regexp
+	}{
+		{core.TaxNet, regexp.MustCompile(`(?i)(\bnet\b|на руки|чистыми|нетто|после вычета)`)},
+		{core.TaxGross, regexp.MustCompile(`(?i)(\bgross\b|гросс|до вычета|брутто|до налогов)`)},
+	}
+
+	// Handles are not preceded by word characters, so emails don't match
+	contactHandle = regexp.MustCompile(`(?:^|[^\w@./])@([A-Za-z][A-Za-z0-9_]{3,31})\b`)
+	contactEmail  = regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)*\.[A-Za-z]{2,}`)
+	contactURL    = regexp.MustCompile(`(?i)(?:https?://|\bt\.me/)[^\s<>"'()\[\]]+`)
+	// Only numbers in international or Russian trunk format, bare digits are salaries
+	contactPhone = regexp.MustCompile(`(?:\+\d{1,3}|\b8)[\s(-]*\d{3}[\s)-]*\d{3}[\s-]*\d{2}[\s-]*\d{2}\b`)
+
+	titlePrefix = regexp.MustCompile(`(?i)^(вакансия|vacancy|позиция|position|должность|role)\s*[:\-–—]\s*`)
+	fieldLine   = regexp.MustCompile(`(?im)^[^\p{L}]*(компания|company|локация|location|город|city)\s*[:\-–—]\s*(.+)$`)
+)
+
+// skillPatterns maps canonical skill names to their spellings.
+var skillPatterns = compileSkills(map[string]string{
+	"Go":            `\bgolang\b|\bGo\b`,
+	"Python":        `(?i)\bpython\b`,
+	"JavaScript":    `(?i)\bjavascript\b|\bJS\b`,
+	"TypeScript":    `(?i)\btypescript\b|\bTS\b`,
+	"React":         `(?i)\breact(?:\.?js)?\b`,
+	"Vue":           `(?i)\bvue(?:\.?js)?\b`,
+	"Svelte":        `(?i)\bsvelte\b`,
+	"SvelteKit":     `(?i)\bsveltekit\b`,
+	"Angular":       `(?i)\bangular\b`,
+	"Node.js":       `(?i)\bnode(?:\.?js)?\b`,
+	"Next.js":       `(?i)\bnext\.?js\b`,
+	"Java":          `(?i)\bjava\b`,
+	"Kotlin":        `(?i)\bkotlin\b`,
+	"Swift":         `(?i)\bswift\b`,
+	"C#":            `(?i)\bc#|\.net\b`,
+	"C++":           `(?i)\bc\+\+`,
+	"PHP":           `(?i)\bphp\b`,
+	"Ruby":          `(?i)\bruby\b`,
+	"Rust":          `(?i)\brust\b`,
+	"Scala":         `(?i)\bscala\b`,
+	"SQL":           `(?i)\bsql\b`,
+	"PostgreSQL":    `(?i)\bpostgres(?:ql)?\b`,
+	"MySQL":         `(?i)\bmysql\b`,
+	"MongoDB":       `(?i)\bmongo(?:db)?\b`,
+	"Redis":         `(?i)\bredis\b`,
+	"ClickHouse":    `(?i)\bclickhouse\b`,
+	"Elasticsearch": `(?i)\belastic(?:search)?\b`,
+	"Kafka":         `(?i)\bkafka\b`,
+	"RabbitMQ":      `(?i)\brabbit(?:mq)?\b`,
+	"Docker":        `(?i)\bdocker\b`,
+	"Kubernetes":    `(?i)\bkubernetes\b|\bk8s\b`,
+	"AWS":           `(?i)\baws\b`,
+	"GCP":           `(?i)\bgcp\b`,
+	"Azure":         `(?i)\bazure\b`,
+	"Terraform":     `(?i)\bterraform\b`,
+	"Linux":         `(?i)\blinux\b`,
+	"Git":           `(?i)\bgit\b`,
+	"CI/CD":         `(?i)\bci/cd\b`,
+	"GraphQL":       `(?i)\bgraphql\b`,
+	"gRPC":          `(?i)\bgrpc\b`,
+	"Django":        `(?i)\bdjango\b`,
+	"FastAPI":       `(?i)\bfastapi\b`,
+	"Flask":         `(?i)\bflask\b`,
+	"Spring":        `(?i)\bspring\b`,
+	"Laravel":       `(?i)\blaravel\b`,
+	"iOS":           `(?i)\bios\b`,
+	"Android":       `(?i)\bandroid\b`,
+	"Flutter":       `(?i)\bflutter\b`,
+	"PyTorch":       `(?i)\bpytorch\b`,
+	"TensorFlow":    `(?i)\btensorflow\b`,
+	"Figma":         `(?i)\bfigma\b`,
+})
+
+type skillPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+func compileSkills(patterns map[string]string) []skillPattern {
+	skills := make([]skillPattern, 0, len(patterns))
+	for name, pattern := range patterns {
+		skills = append(skills, skillPattern{name: name, re: regexp.MustCompile(pattern)})
+	}
+	return skills
+}
+
+// RuleExtractor implements core.JobExtractor with regular expressions and
+// keyword dictionaries. It needs no network, so it serves as a fallback when
+// the LLM is unavailable and as a pre-fill to sanity check LLM output.
+type RuleExtractor struct{}
+
+// NewRuleExtractor creates a new rule-based extractor.
+func NewRuleExtractor() *RuleExtractor {
+	return &RuleExtractor{}
+}
+
+// Extract parses job posting text into ParsedData.
+// Implements core.JobExtractor interface.
+func (r *RuleExtractor) Extract(ctx context.Context, text string) (core.Extraction, error) {
+	lower := strings.ToLower(text)
+
+	data := core.ParsedData{
+		Title:    extractTitle(text),
+		Skills:   extractSkills(text),
+		IsRemote: containsAny(lower, remoteKeywords),
+		Grade:    extractGrade(text),
+		Contacts: extractContacts(text),
+	}
+	data.SalaryMin, data.SalaryMax, data.Currency = extractSalary(text)
+	if data.SalaryMin > 0 || data.SalaryMax > 0 {
+		data.SalaryPeriod, data.SalaryTax = extractSalaryTerms(text)
+	}
+
+	for _, m := range fieldLine.FindAllStringSubmatch(text, -1) {
+		value := strings.TrimSpace(m[2])
+		switch strings.ToLower(m[1]) {
+		case "компания", "company":
+			data.Company = value
+		default:
+			data.Location = value
+		}
+	}
+
+	// A price alone is not enough, "продам гараж, 300 000 р." has one too
+	hasSalary := data.SalaryMin > 0 || data.SalaryMax > 0
+	hasRole := len(data.Skills) > 0 || data.Grade != ""
+	data.IsVacancy = data.Title != "" && (containsAny(lower, vacancyKeywords) || (hasSalary && hasRole))
+
+	return core.Extraction{
+		Data: data,
+		Provenance: core.Provenance{
+			Extractor:     "rules",
+			PromptVersion: rulesVersion,
+			ExtractedAt:   time.Now(),
+		},
+	}, nil
+}
+
+// extractTitle returns the first meaningful line without markup and
+// "Vacancy:" style prefixes.
+func extractTitle(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimLeftFunc(line, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '#'
+		})
+		line = strings.TrimRightFunc(line, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ')'
+		})
+
+		// Skip hashtag-only lines like "#вакансия #golang"
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimSpace(titlePrefix.ReplaceAllString(line, ""))
+		line = strings.Trim(line, "*_`")
+		if line == "" {
+			continue
+		}
+
+		if utf8.RuneCountInString(line) > maxTitleLength {
+			line = string([]rune(line)[:maxTitleLength])
+		}
+		return line
+	}
+	return ""
+}
+
+// extractContacts returns Telegram handles, emails, phones and links in text.
+func extractContacts(text string) []core.Contact {
+	var contacts []core.Contact
+
+	for _, m := range contactHandle.FindAllStringSubmatch(text, -1) {
+		contacts = append(contacts, core.Contact{Kind: core.ContactTelegram, Value: m[1]})
+	}
+	for _, email := range contactEmail.FindAllString(text, -1) {
+		contacts = append(contacts, core.Contact{Kind: core.ContactEmail, Value: email})
+	}
+	for _, phone := range contactPhone.FindAllString(text, -1) {
+		contacts = append(contacts, core.Contact{Kind: core.ContactPhone, Value: phone})
+	}
+	for _, link := range contactURL.FindAllString(text, -1) {
+		if contact, ok := core.ParseLink(strings.TrimRight(link, ".,;:!?")); ok {
+			contacts = append(contacts, contact)
+		}
+	}
+
+	return core.MergeContacts(contacts)
+}
+
+// extractSkills returns dictionary skills mentioned in text, in text order.
+func extractSkills(text string) []string {
+	type found struct {
+		name string
+		pos  int
+	}
+
+	var matches []found
+	for _, skill := range skillPatterns {
+		if loc := skill.re.FindStringIndex(text); loc != nil {
+			matches = append(matches, found{skill.name, loc[0]})
+		}
+	}
+
+	// Insertion sort, the list is short
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].pos < matches[j-1].pos; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+
+	skills := make([]string, 0, len(matches))
+	for _, m := range matches {
+		skills = append(skills, m.name)
+	}
+	return skills
+}
+
+// extractGrade returns the grade mentioned first in text.
+func extractGrade(text string) string {
+	grade, first := "", -1
+	for _, g := range gradePatterns {
+		if loc := g.re.FindStringIndex(text); loc != nil && (first < 0 || loc[0] < first) {
+			grade, first = g.grade, loc[0]
+		}
+	}
+	return grade
+}
+
+// extractSalary returns the first salary mention. Amounts without a currency
+// are only accepted with a "k" suffix on a line that talks about salary.
+func extractSalary(text string) (minSalary, maxSalary int, currency string) {
+	for _, line := range strings.Split(text, "\n") {
+		lowerLine := strings.ToLower(line)
+
+		for _, m := range salaryPattern.FindAllStringSubmatch(line, -1) {
+			qualifier := strings.ToLower(m[1])
+			cur := currencyCode(m[2], m[5], m[8])
+
+			from, fromK := parseAmount(m[3]), m[4] != ""
+			to, toK := parseAmount(m[6]), m[7] != ""
+
+			if cur == "" && !((fromK || toK) && containsAny(lowerLine, salaryKeywords)) {
+				continue
+			}
+
+			// "200-250к": the suffix applies to both ends
+			if toK && !fromK && from < 1000 {
+				fromK = true
+			}
+			if fromK {
+				from *= 1000
+			}
+			if toK {
+				to *= 1000
+			}
+
+			// Hourly rates can be as low as two digits
+			if from < 10 {
+				continue
+			}
+
+			switch {
+			case to > 0:
+				return int(from), int(to), cur
+			case qualifier == "до" || qualifier == "up to":
+				return 0, int(from), cur
+			case qualifier == "от" || qualifier == "from":
+				return int(from), 0, cur
+			default:
+				return int(from), int(from), cur
+			}
+		}
+	}
+	return 0, 0, ""
+}
+
+// extractSalaryTerms returns the salary period and gross/net marker.
+func extractSalaryTerms(text string) (core.SalaryPeriod, core.SalaryTax) {
+	var (
+		period core.SalaryPeriod
+		tax    core.SalaryTax
+	)
+
+	for _, p := range periodPatterns {
+		if p.re.MatchString(text) {
+			period = p.period
+			break
+		}
+	}
+
+	for _, t := range taxPatterns {
+		if t.re.MatchString(text) {
+			tax = t.tax
+			break
+		}
+	}
+
+	return period, tax
+}
+
+// currencyCode returns the ISO code of the first non-empty currency token.
+func currencyCode(tokens ...string) string {
+	for _, token := range tokens {
+		token = strings.TrimSuffix(strings.ToLower(token), ".")
+		for prefix, code := range currencyCodes {
+			if token != "" && strings.HasPrefix(token, prefix) {
+				return code
+			}
+		}
+	}
+	return ""
+}
+
+// parseAmount parses "150 000", "3,000" or "2.5" (as in "2.5k").
+func parseAmount(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	s = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\u00a0' {
+			return -1
+		}
+		return r
+	}, s)
+
+	// A separator followed by exactly three digits groups thousands
+	if i := strings.LastIndexAny(s, ".,"); i >= 0 && len(s)-i-1 == 3 {
+		s = strings.NewReplacer(".", "", ",", "").Replace(s)
+	}
+	s = strings.ReplaceAll(s, ",", ".")
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func containsAny(s string, keywords []string) bool {
+	for _, kw := range keywords {
+		if strings.Contains(s, kw) {
+			return true
+		}
+	}
+	return false
+}
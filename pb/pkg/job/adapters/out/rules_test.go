@@ -0,0 +1,281 @@
+package out
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"svpb-tmpl/pkg/job/core"
+)
+
+func TestExtractSalary(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		min, max int
+		currency string
+	}{
+		{"range with trailing currency", "Зарплата 200 000 - 250 000 руб", 200000, 250000, "RUB"},
+		{"k suffix on both ends", "вилка 200-250к ₽", 200000, 250000, "RUB"},
+		{"from", "от 3000$", 3000, 0, "USD"},
+		{"up to", "up to $5000", 0, 5000, "USD"},
+		{"from and to", "от 150 000 до 200 000 руб", 150000, 200000, "RUB"},
+		{"single amount", "Salary: 4500 EUR", 4500, 4500, "EUR"},
+		{"k suffix with currency", "$5k/mo", 5000, 5000, "USD"},
+		{"fractional k", "€2.5k", 2500, 2500, "EUR"},
+		{"thousands with comma", "£3,000 - £4,000", 3000, 4000, "GBP"},
+		{"hourly rate", "$50/hour", 50, 50, "USD"},
+		{"k without currency on salary line", "ЗП: 300к", 300000, 300000, ""},
+		{"k without currency elsewhere", "Команда 10k пользователей", 0, 0, ""},
+		{"bare number", "Опыт от 3 лет, 40 часов в неделю", 0, 0, ""},
+		{"first line wins", "Go developer\n$4000\n€3000", 4000, 4000, "USD"},
+		{"too small", "$5", 0, 0, ""},
+		{"no salary", "Ищем Go разработчика", 0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minSalary, maxSalary, currency := extractSalary(tt.text)
+			if minSalary != tt.min || maxSalary != tt.max || currency != tt.currency {
+				t.Errorf("extractSalary(%q) = %d, %d, %q, want %d, %d, %q",
+					tt.text, minSalary, maxSalary, currency, tt.min, tt.max, tt.currency)
+			}
+		})
+	}
+}
+
+func TestExtractSalaryTerms(t *testing.T) {
+	tests := []struct {
+		text   string
+		period core.SalaryPeriod
+		tax    core.SalaryTax
+	}{
+		{"$50/hour", core.PeriodHour, ""},
+		{"1500 руб в час", core.PeriodHour, ""},
+		{"$120k/year gross", core.PeriodYear, core.TaxGross},
+		{"годовой доход 3 млн", core.PeriodYear, ""},
+		{"$5k/mo net", core.PeriodMonth, core.TaxNet},
+		{"300 000 руб в месяц на руки", core.PeriodMonth, core.TaxNet},
+		{"200 000 руб до вычета НДФЛ", "", core.TaxGross},
+		// The hour marker is more specific than the month one
+		{"$40 per hour, paid monthly", core.PeriodHour, ""},
+		{"$4000", "", ""},
+		// Words merely containing a marker
+		{"$4000, Hyderabad, internet provider", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			period, tax := extractSalaryTerms(tt.text)
+			if period != tt.period || tax != tt.tax {
+				t.Errorf("extractSalaryTerms(%q) = %q, %q, want %q, %q", tt.text, period, tax, tt.period, tt.tax)
+			}
+		})
+	}
+}
+
+func TestExtractGrade(t *testing.T) {
+	tests := []struct {
+		text  string
+		grade string
+	}{
+		{"Senior Go Developer", "Senior"},
+		{"Ищем сеньора в команду", "Senior"},
+		{"Junior/Middle Python", "Junior"},
+		{"Middle+ / Senior frontend", "Middle"},
+		{"Team Lead backend", "Lead"},
+		{"Тимлид платформы", "Lead"},
+		{"Solution Architect", "Principal"},
+		// "lead" inside a word is not a grade
+		{"Leading fintech company hires Go developer", ""},
+		{"Go developer", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := extractGrade(tt.text); got != tt.grade {
+				t.Errorf("extractGrade(%q) = %q, want %q", tt.text, got, tt.grade)
+			}
+		})
+	}
+}
+
+func TestExtractTitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		title string
+	}{
+		{"first line", "Go Developer\nКомпания: Acme", "Go Developer"},
+		{"hashtags and emoji skipped", "#вакансия #golang\n\n🔥 **Senior Go Developer** 🔥\nRemote", "Senior Go Developer"},
+		{"prefix dropped", "Вакансия: Backend разработчик (Go)", "Backend разработчик (Go)"},
+		{"english prefix", "Position - QA Engineer", "QA Engineer"},
+		{"markup only line skipped", "***\nData Engineer", "Data Engineer"},
+		{"empty", "\n #hiring \n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractTitle(tt.text); got != tt.title {
+				t.Errorf("extractTitle(%q) = %q, want %q", tt.text, got, tt.title)
+			}
+		})
+	}
+}
+
+func TestExtractTitleTruncated(t *testing.T) {
+	long := ""
+	for range maxTitleLength + 20 {
+		long += "я"
+	}
+
+	got := []rune(extractTitle(long))
+	if len(got) != maxTitleLength {
+		t.Errorf("extractTitle length = %d, want %d", len(got), maxTitleLength)
+	}
+}
+
+func TestExtractContacts(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		contacts []core.Contact
+	}{
+		{
+			name:     "telegram handle",
+			text:     "Пишите @hr_anna",
+			contacts: []core.Contact{{Kind: core.ContactTelegram, Value: "hr_anna"}},
+		},
+		{
+			name:     "email is not a handle",
+			text:     "CV to jobs@acme.io",
+			contacts: []core.Contact{{Kind: core.ContactEmail, Value: "jobs@acme.io"}},
+		},
+		{
+			name:     "russian trunk phone",
+			text:     "Звоните 8 (999) 123-45-67",
+			contacts: []core.Contact{{Kind: core.ContactPhone, Value: "+79991234567"}},
+		},
+		{
+			name:     "international phone",
+			text:     "WhatsApp +44 207 123 45 67",
+			contacts: []core.Contact{{Kind: core.ContactPhone, Value: "+442071234567"}},
+		},
+		{
+			name:     "salary is not a phone",
+			text:     "ЗП 300000 руб",
+			contacts: nil,
+		},
+		{
+			name:     "t.me link becomes a handle",
+			text:     "Откликнуться: t.me/hr_anna.",
+			contacts: []core.Contact{{Kind: core.ContactTelegram, Value: "hr_anna"}},
+		},
+		{
+			name:     "form link",
+			text:     "Анкета: https://forms.gle/abc123",
+			contacts: []core.Contact{{Kind: core.ContactForm, Value: "https://forms.gle/abc123"}},
+		},
+		{
+			name: "duplicates merged",
+			text: "@hr_anna или https://t.me/hr_anna, резюме на HR@acme.io",
+			contacts: []core.Contact{
+				{Kind: core.ContactTelegram, Value: "hr_anna"},
+				{Kind: core.ContactEmail, Value: "hr@acme.io"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractContacts(tt.text); !slices.Equal(got, tt.contacts) {
+				t.Errorf("extractContacts(%q) = %v, want %v", tt.text, got, tt.contacts)
+			}
+		})
+	}
+}
+
+func TestExtractSkills(t *testing.T) {
+	text := "Stack: PostgreSQL, golang, Kubernetes (k8s), Kafka. Go is a must"
+	want := []string{"PostgreSQL", "Go", "Kubernetes", "Kafka"}
+
+	if got := extractSkills(text); !slices.Equal(got, want) {
+		t.Errorf("extractSkills(%q) = %v, want %v", text, got, want)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"150 000", 150000},
+		{"150\u00a0000", 150000},
+		{"3,000", 3000},
+		{"3.000", 3000},
+		{"1 500 000", 1500000},
+		{"2.5", 2.5},
+		{"2,5", 2.5},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := parseAmount(tt.in); got != tt.want {
+				t.Errorf("parseAmount(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleExtractorIsVacancy(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		vacancy bool
+	}{
+		{"vacancy keyword", "Go Developer\nИщем разработчика в команду", true},
+		{"salary and role", "Senior Python\n$5000", true},
+		{"salary alone", "Продам гараж\n300 000 руб.", false},
+		{"no title", "#вакансия", false},
+	}
+
+	extractor := NewRuleExtractor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extraction, err := extractor.Extract(context.Background(), tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if extraction.Data.IsVacancy != tt.vacancy {
+				t.Errorf("IsVacancy(%q) = %v, want %v", tt.text, extraction.Data.IsVacancy, tt.vacancy)
+			}
+		})
+	}
+}
+
+func TestRuleExtractorFields(t *testing.T) {
+	text := "Вакансия: Senior Go Developer\nКомпания: Acme\nЛокация: Remote\nЗП: $6000-7000 в месяц gross\nПишите @hr_anna"
+
+	extraction, err := NewRuleExtractor().Extract(context.Background(), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := extraction.Data
+	if data.Title != "Senior Go Developer" || data.Company != "Acme" || data.Location != "Remote" {
+		t.Errorf("title, company, location = %q, %q, %q", data.Title, data.Company, data.Location)
+	}
+	if data.SalaryMin != 6000 || data.SalaryMax != 7000 || data.Currency != "USD" {
+		t.Errorf("salary = %d-%d %s", data.SalaryMin, data.SalaryMax, data.Currency)
+	}
+	if data.SalaryPeriod != core.PeriodMonth || data.SalaryTax != core.TaxGross {
+		t.Errorf("salary terms = %q, %q", data.SalaryPeriod, data.SalaryTax)
+	}
+	if data.Grade != "Senior" || !data.IsRemote || !data.IsVacancy {
+		t.Errorf("grade, remote, vacancy = %q, %v, %v", data.Grade, data.IsRemote, data.IsVacancy)
+	}
+	if extraction.Provenance.Extractor != "rules" || extraction.Provenance.PromptVersion != rulesVersion {
+		t.Errorf("provenance = %+v", extraction.Provenance)
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import "context"
+
+// ExtractionCacheStats reports extraction cache effectiveness.
+type ExtractionCacheStats struct {
+	// Hits and Misses are counted since process start. Calls that skip
+	// the cache count as neither.
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+
+	// Entries, TotalHits and TokensSaved are read from the cache collection.
+	Entries     int `json:"entries"`
+	TotalHits   int `json:"totalHits"`
+	TokensSaved int `json:"tokensSaved"`
+}
+
+type cacheModeKey struct{}
+
+// cacheMode is how cached extractors treat a call.
+type cacheMode int
+
+const (
+	cacheRefresh cacheMode = iota + 1
+	cacheBypass
+)
+
+// SkipCache returns a context that makes cached extractors call the
+// underlying extractor and refresh the cache entry.
+func SkipCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheModeKey{}, cacheRefresh)
+}
+
+// BypassCache returns a context that makes cached extractors call the
+// underlying extractor without reading or writing the cache, e.g. for dry
+// runs.
+func BypassCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheModeKey{}, cacheBypass)
+}
+
+// CacheSkipped reports whether SkipCache or BypassCache was applied to ctx.
+func CacheSkipped(ctx context.Context) bool {
+	mode, _ := ctx.Value(cacheModeKey{}).(cacheMode)
+	return mode != 0
+}
+
+// CacheBypassed reports whether BypassCache was applied to ctx.
+func CacheBypassed(ctx context.Context) bool {
+	mode, _ := ctx.Value(cacheModeKey{}).(cacheMode)
+	return mode == cacheBypass
+}
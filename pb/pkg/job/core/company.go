@@ -0,0 +1,113 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Intervals of company stats.
+const (
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// ErrCompanyNotFound is returned for unknown company ids.
+var ErrCompanyNotFound = errors.New("company not found")
+
+// Company is an employer with the spellings that resolve to it.
+type Company struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases"`
+	Contacts []string `json:"contacts"`
+	Websites []string `json:"websites"`
+}
+
+// CompanyPosting is a vacancy of a company, still open or closed or
+// withdrawn since, as told by Status.
+type CompanyPosting struct {
+	ID               string         `db:"id" json:"id"`
+	Title            string         `db:"title" json:"title"`
+	Status           JobStatus      `db:"status" json:"status"`
+	Grade            string         `db:"grade" json:"grade"`
+	Location         string         `db:"location" json:"location"`
+	IsRemote         bool           `db:"isRemote" json:"isRemote"`
+	SalaryMinMonthly int            `db:"salaryMinMonthly" json:"salaryMinMonthly"`
+	SalaryMaxMonthly int            `db:"salaryMaxMonthly" json:"salaryMaxMonthly"`
+	URL              string         `db:"url" json:"url"`
+	Created          types.DateTime `db:"created" json:"created"`
+}
+
+// CompanyPeriod aggregates postings of a company over a week or month.
+// Salaries are monthly amounts in the base currency, 0 if none were known.
+type CompanyPeriod struct {
+	Period    string `db:"period" json:"period"`
+	Postings  int    `db:"postings" json:"postings"`
+	SalaryMin int    `db:"salaryMin" json:"salaryMin"`
+	SalaryMax int    `db:"salaryMax" json:"salaryMax"`
+}
+
+// CompanyStats summarizes hiring activity of a company.
+type CompanyStats struct {
+	Company      Company         `json:"company"`
+	Postings     int             `json:"postings"`
+	FirstSeen    types.DateTime  `json:"firstSeen"`
+	LastSeen     types.DateTime  `json:"lastSeen"`
+	PerMonth     float64         `json:"perMonth"`
+	BaseCurrency string          `json:"baseCurrency"`
+	Interval     string          `json:"interval"`
+	Periods      []CompanyPeriod `json:"periods"`
+}
+
+// legalForms are dropped from company keys.
+var legalForms = map[string]bool{
+	"llc": true, "ltd": true, "inc": true, "corp": true, "co": true, "gmbh": true,
+	"plc": true, "bv": true, "sa": true, "ag": true, "limited": true, "company": true,
+	"ооо": true, "оао": true, "зао": true, "пао": true, "ао": true, "ип": true, "тоо": true,
+}
+
+// cyrillicLatin transliterates Cyrillic letters for company keys.
+var cyrillicLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ў': "u",
+}
+
+// CompanyKey normalizes a company name for matching. Case, punctuation,
+// legal forms and script are ignored, so "ООО «Яндекс»", "Yandex LLC" and
+// "yandex" share the key "yandex".
+func CompanyKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var parts []string
+	for _, word := range words {
+		if legalForms[word] {
+			continue
+		}
+		parts = append(parts, transliterate(word))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func transliterate(word string) string {
+	// Brands usually spell "кс" as "x": Яндекс -> yandex
+	word = strings.ReplaceAll(word, "кс", "x")
+
+	var b strings.Builder
+	for _, r := range word {
+		if latin, ok := cyrillicLatin[r]; ok {
+			b.WriteString(latin)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package core
+
+import "testing"
+
+func TestCompanyKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"yandex", "yandex"},
+		{"Yandex LLC", "yandex"},
+		{"ООО «Яндекс»", "yandex"},
+		{"Яндекс", "yandex"},
+		{"Acme, Inc.", "acme"},
+		{"Acme GmbH", "acme"},
+		{"Тинькофф Банк", "tinkoff bank"},
+		{"Kaspersky Lab", "kaspersky lab"},
+		{"2GIS", "2gis"},
+		{"  ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompanyKey(tt.name); got != tt.want {
+				t.Errorf("CompanyKey(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ContactKind is how to reach the recruiter.
+type ContactKind string
+
+const (
+	ContactTelegram ContactKind = "telegram"
+	ContactEmail    ContactKind = "email"
+	ContactPhone    ContactKind = "phone"
+	ContactURL      ContactKind = "url"
+	ContactForm     ContactKind = "form"
+)
+
+// Contact is a way to apply for a job.
+type Contact struct {
+	Kind  ContactKind `json:"kind" enum:"telegram,email,phone,url,form"`
+	Value string      `json:"value" description:"Telegram username without @, email, phone in international format or full URL"`
+}
+
+// ContactLink is a contact with a deep link the UI can open directly.
+type ContactLink struct {
+	Contact
+	Link string `json:"link"`
+}
+
+var (
+	telegramHandle = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{3,31}$`)
+
+	// Hosts of application forms, the rest are plain apply URLs
+	formHosts = []string{
+		"forms.gle", "docs.google.com/forms", "forms.yandex.ru", "forms.office.com",
+		"typeform.com", "tally.so", "airtable.com", "jotform.com",
+	}
+
+	// Paths of t.me that are not usernames
+	telegramReserved = map[string]bool{
+		"c": true, "joinchat": true, "addstickers": true, "share": true, "proxy": true, "s": true,
+	}
+)
+
+// ParseLink classifies a URL found in a message: t.me links become Telegram
+// contacts, mailto: and tel: become emails and phones.
+func ParseLink(raw string) (Contact, bool) {
+	raw = strings.TrimSpace(raw)
+	lower := strings.ToLower(raw)
+
+	switch {
+	case strings.HasPrefix(lower, "mailto:"):
+		return NormalizeContact(Contact{Kind: ContactEmail, Value: raw[len("mailto:"):]})
+	case strings.HasPrefix(lower, "tel:"):
+		return NormalizeContact(Contact{Kind: ContactPhone, Value: raw[len("tel:"):]})
+	case strings.HasPrefix(lower, "tg://resolve?domain="):
+		return NormalizeContact(Contact{Kind: ContactTelegram, Value: raw[len("tg://resolve?domain="):]})
+	}
+
+	return NormalizeContact(Contact{Kind: ContactURL, Value: raw})
+}
+
+// NormalizeContact cleans up a contact and checks it is usable.
+// URLs pointing at known form services become ContactForm.
+func NormalizeContact(c Contact) (Contact, bool) {
+	value := strings.TrimSpace(c.Value)
+
+	switch c.Kind {
+	case ContactTelegram:
+		if handle, ok := telegramUsername(value); ok {
+			value = handle
+		}
+		value = strings.TrimPrefix(value, "@")
+		if !telegramHandle.MatchString(value) {
+			return Contact{}, false
+		}
+
+	case ContactEmail:
+		addr, err := mail.ParseAddress(strings.TrimPrefix(value, "mailto:"))
+		if err != nil {
+			return Contact{}, false
+		}
+		value = strings.ToLower(addr.Address)
+
+	case ContactPhone:
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, value)
+		if len(digits) < 10 || len(digits) > 15 {
+			return Contact{}, false
+		}
+		// Russian numbers are often written as 8 (999) ...
+		if len(digits) == 11 && digits[0] == '8' {
+			digits = "7" + digits[1:]
+		}
+		value = "+" + digits
+
+	case ContactURL, ContactForm:
+		if handle, ok := telegramUsername(value); ok {
+			return Contact{Kind: ContactTelegram, Value: handle}, true
+		}
+		if !strings.Contains(value, "://") {
+			value = "https://" + value
+		}
+		u, err := url.Parse(value)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return Contact{}, false
+		}
+		value = u.String()
+
+		// Posts and invite links are not someone to write to
+		if host := strings.TrimPrefix(strings.ToLower(u.Host), "www."); host == "t.me" || host == "telegram.me" {
+			return Contact{}, false
+		}
+
+		c.Kind = ContactURL
+		hostPath := strings.TrimPrefix(strings.ToLower(u.Host), "www.") + u.Path
+		for _, host := range formHosts {
+			if strings.HasPrefix(hostPath, host) || strings.HasSuffix(u.Host, "."+host) {
+				c.Kind = ContactForm
+				break
+			}
+		}
+
+	default:
+		return Contact{}, false
+	}
+
+	return Contact{Kind: c.Kind, Value: value}, true
+}
+
+// DeepLink returns a link opening the contact: a tg://resolve link to the
+// Telegram chat, mailto:, tel: or the URL itself.
+func (c Contact) DeepLink() string {
+	switch c.Kind {
+	case ContactTelegram:
+		return "tg://resolve?domain=" + c.Value
+	case ContactEmail:
+		return "mailto:" + c.Value
+	case ContactPhone:
+		return "tel:" + c.Value
+	default:
+		return c.Value
+	}
+}
+
+// MergeContacts normalizes contacts and drops invalid ones and duplicates,
+// keeping the first occurrence.
+func MergeContacts(lists ...[]Contact) []Contact {
+	var merged []Contact
+	seen := make(map[string]bool)
+
+	for _, list := range lists {
+		for _, c := range list {
+			c, ok := NormalizeContact(c)
+			if !ok {
+				continue
+			}
+
+			key := strings.ToLower(string(c.Kind) + ":" + c.Value)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, c)
+		}
+	}
+
+	return merged
+}
+
+// WithoutChannel drops the Telegram contact of the channel a vacancy was
+// posted in, e.g. its "@channel" footer. It is no one to write to.
+func WithoutChannel(contacts []Contact, username string) []Contact {
+	if username == "" {
+		return contacts
+	}
+	return slices.DeleteFunc(slices.Clone(contacts), func(c Contact) bool {
+		return c.Kind == ContactTelegram && strings.EqualFold(c.Value, username)
+	})
+}
+
+// telegramUsername extracts the username from t.me links.
+func telegramUsername(raw string) (string, bool) {
+	value := strings.TrimPrefix(strings.TrimPrefix(raw, "https://"), "http://")
+	for _, host := range []string{"t.me/", "telegram.me/", "www.t.me/"} {
+		if rest, ok := strings.CutPrefix(strings.ToLower(value), host); ok {
+			name := value[len(value)-len(rest):]
+			name, _, _ = strings.Cut(name, "?")
+			// t.me/channel/123 is a post, not someone to write to
+			name, post, _ := strings.Cut(name, "/")
+			if post != "" || telegramReserved[strings.ToLower(name)] || !telegramHandle.MatchString(name) {
+				return "", false
+			}
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,69 @@
+package core
+
+import "testing"
+
+func TestParseLink(t *testing.T) {
+	tests := []struct {
+		link string
+		want Contact
+		ok   bool
+	}{
+		{"https://t.me/hr_anna", Contact{ContactTelegram, "hr_anna"}, true},
+		{"t.me/hr_anna", Contact{ContactTelegram, "hr_anna"}, true},
+		{"tg://resolve?domain=hr_anna", Contact{ContactTelegram, "hr_anna"}, true},
+		{"mailto:HR@Acme.io", Contact{ContactEmail, "hr@acme.io"}, true},
+		{"tel:+7 (999) 123-45-67", Contact{ContactPhone, "+79991234567"}, true},
+		{"https://acme.io/careers/go", Contact{ContactURL, "https://acme.io/careers/go"}, true},
+		{"https://forms.gle/abc123", Contact{ContactForm, "https://forms.gle/abc123"}, true},
+		{"https://docs.google.com/forms/d/abc", Contact{ContactForm, "https://docs.google.com/forms/d/abc"}, true},
+		{"https://acme.typeform.com/to/abc", Contact{ContactForm, "https://acme.typeform.com/to/abc"}, true},
+		// Posts and invite links are not someone to write to
+		{"https://t.me/golang_jobs/123", Contact{}, false},
+		{"https://t.me/joinchat/abc", Contact{}, false},
+		{"https://t.me/c/123/45", Contact{}, false},
+		{"javascript:alert(1)", Contact{}, false},
+		{"ftp://acme.io/cv", Contact{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			got, ok := ParseLink(tt.link)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ParseLink(%q) = %v, %v, want %v, %v", tt.link, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNormalizeContact(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Contact
+		want Contact
+		ok   bool
+	}{
+		{"handle with at", Contact{ContactTelegram, " @hr_anna "}, Contact{ContactTelegram, "hr_anna"}, true},
+		{"handle as link", Contact{ContactTelegram, "https://t.me/hr_anna"}, Contact{ContactTelegram, "hr_anna"}, true},
+		{"handle too short", Contact{ContactTelegram, "@abc"}, Contact{}, false},
+		{"handle with spaces", Contact{ContactTelegram, "hr anna"}, Contact{}, false},
+		{"email lowercased", Contact{ContactEmail, "HR@Acme.io"}, Contact{ContactEmail, "hr@acme.io"}, true},
+		{"email with name", Contact{ContactEmail, "Anna <anna@acme.io>"}, Contact{ContactEmail, "anna@acme.io"}, true},
+		{"invalid email", Contact{ContactEmail, "anna at acme"}, Contact{}, false},
+		{"russian trunk phone", Contact{ContactPhone, "8 (999) 123-45-67"}, Contact{ContactPhone, "+79991234567"}, true},
+		{"international phone", Contact{ContactPhone, "+44 20 7123 4567"}, Contact{ContactPhone, "+442071234567"}, true},
+		{"phone too short", Contact{ContactPhone, "123-45-67"}, Contact{}, false},
+		{"url without scheme", Contact{ContactURL, "acme.io/jobs"}, Contact{ContactURL, "https://acme.io/jobs"}, true},
+		{"form host as url", Contact{ContactURL, "https://tally.so/r/abc"}, Contact{ContactForm, "https://tally.so/r/abc"}, true},
+		{"telegram link as url", Contact{ContactURL, "https://t.me/hr_anna"}, Contact{ContactTelegram, "hr_anna"}, true},
+		{"unknown kind", Contact{"fax", "123"}, Contact{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeContact(tt.in)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("NormalizeContact(%v) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package core
+
+import "regexp"
+
+// EditResult reports what Edit did with an edited message.
+type EditResult struct {
+	// JobID is the job the message was collected as, empty if it never was
+	JobID string
+	// Updated is true if the job's text changed and was saved
+	Updated bool
+	// Closed is true if the edit marked the vacancy as closed
+	Closed bool
+	// Reopened is true if the edit removed the text a closed job was closed by
+	Reopened bool
+}
+
+// closedMarkers match phrases posters add to a vacancy once it is taken.
+// They match whole words only: "disclosed" and "закрытый клуб" are no
+// markers.
+var closedMarkers = []*regexp.Regexp{
+	// English
+	markerPattern(`closed|position (?:is )?filled|has been filled|no longer (?:available|relevant|open)`),
+	// Russian
+	markerPattern(`закрыт[аоы]?|не ?актуальн(?:а|о|ы|ая|ое|ые)?|уже нашли|набор заверш[её]н|вакансия занята`),
+}
+
+// markerPattern matches any of the alternatives as whole words, Cyrillic
+// included, which \b doesn't support.
+func markerPattern(alternatives string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(?:^|[^\p{L}])(?:` + alternatives + `)(?:$|[^\p{L}])`)
+}
+
+// ClosedByEdit returns true if after adds a closed marker to before, e.g. a
+// "CLOSED" line on top of the post. Markers already present in the original
+// text ("closed beta") don't count.
+func ClosedByEdit(before, after string) bool {
+	for _, marker := range closedMarkers {
+		if len(marker.FindAllStringIndex(after, -1)) > len(marker.FindAllStringIndex(before, -1)) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,10 +3,27 @@ package core
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var (
+	// ErrJobNotFound is returned for unknown job ids.
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrJobUnavailable is returned for jobs closed or withdrawn by their poster.
+	ErrJobUnavailable = errors.New("job is no longer available")
+
+	// ErrNotProcessable is returned for jobs that are neither raw nor failed,
+	// e.g. already processed or closed while queued.
+	ErrNotProcessable = errors.New("job is not processable")
 )
 
 // Job is the aggregate root for job vacancy domain.
@@ -25,9 +42,45 @@ func NewJob(record *core.Record) *Job {
 func NewRawJob(collection *core.Collection, input RawJobInput) *Job {
 	record := core.NewRecord(collection)
 
-	// Extract preliminary title from first line
+	record.Set("title", previewTitle(input.OriginalText))
+	record.Set("originalText", input.OriginalText)
+	record.Set("originalHtml", input.HTML)
+	record.Set("expandedText", input.ExpandedText)
+	record.Set("channelId", fmt.Sprintf("%d", input.ChannelID))
+	record.Set("messageId", input.MessageID)
+	record.Set("hash", input.Hash)
+	record.Set("raw", input.RawData)
+	record.Set("messageContacts", MergeContacts(input.Contacts))
+	record.Set("status", string(StatusRaw))
+	record.Set("url", input.URL)
+	record.Set("unlinkable", input.URL == "")
+	record.Set("simhash", NewSimHash(input.OriginalText).String())
+	record.Set("priority", input.Priority)
+	record.Set("documentText", input.DocumentText)
+	if input.AlbumID != 0 {
+		record.Set("albumId", strconv.FormatInt(input.AlbumID, 10))
+		record.Set("albumMessageIds", input.AlbumMessageIDs)
+	}
+
+	// The first sighting is the message itself
+	seenAt := types.NowDateTime()
+	if !input.PostedAt.IsZero() {
+		seenAt, _ = types.ParseDateTime(input.PostedAt)
+	}
+	record.Set("seenCount", 1)
+	record.Set("firstSeen", seenAt)
+	record.Set("lastSeen", seenAt)
+
+	job := &Job{record: record}
+	job.attach(input.Attachments)
+
+	return job
+}
+
+// previewTitle extracts a preliminary title from the first line of a text.
+func previewTitle(text string) string {
 	title := "Pending Analysis"
-	lines := strings.Split(input.OriginalText, "\n")
+	lines := strings.Split(text, "\n")
 	if len(lines) > 0 && len(lines[0]) > 0 {
 		firstLine := lines[0]
 		if utf8.RuneCountInString(firstLine) > 100 {
@@ -37,17 +90,24 @@ func NewRawJob(collection *core.Collection, input RawJobInput) *Job {
 			title = firstLine
 		}
 	}
+	return title
+}
 
-	record.Set("title", title)
-	record.Set("originalText", input.OriginalText)
-	record.Set("channelId", fmt.Sprintf("%d", input.ChannelID))
-	record.Set("messageId", input.MessageID)
-	record.Set("hash", input.Hash)
-	record.Set("raw", input.RawData)
-	record.Set("status", string(StatusRaw))
+// NewSiblingJob creates a job for a further vacancy of a multi-vacancy post.
+// It shares the source message with parent and starts in processing state,
+// ready to be completed with its own extraction.
+func NewSiblingJob(collection *core.Collection, parent *Job, subIndex int) *Job {
+	record := core.NewRecord(collection)
 
-	url := fmt.Sprintf("https://t.me/c/%d/%d", input.ChannelID, input.MessageID)
-	record.Set("url", url)
+	for _, field := range []string{
+		"title", "originalText", "originalHtml", "expandedText", "channelId", "messageId", "hash", "url", "unlinkable", "messageContacts",
+		"seenCount", "firstSeen", "lastSeen", "priority",
+	} {
+		record.Set(field, parent.record.Get(field))
+	}
+	record.Set("subIndex", subIndex)
+	record.Set("status", string(StatusProcessing))
+	record.Set("attempts", 1)
 
 	return &Job{record: record}
 }
@@ -69,11 +129,130 @@ func (j *Job) OriginalText() string {
 	return j.record.GetString("originalText")
 }
 
+// ExtractionText returns the text to extract the job from: the original
+// text with hidden links written out, the original text for jobs collected
+// without it.
+func (j *Job) ExtractionText() string {
+	if text := j.record.GetString("expandedText"); text != "" {
+		return text
+	}
+	return j.OriginalText()
+}
+
+// ChannelUsername returns the public username of the channel the job was
+// posted in, taken from its post URL. Empty for private chats.
+func (j *Job) ChannelUsername() string {
+	path, ok := strings.CutPrefix(j.record.GetString("url"), "https://t.me/")
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(path, "/")
+	if telegramReserved[strings.ToLower(name)] || !telegramHandle.MatchString(name) {
+		return ""
+	}
+	return name
+}
+
+// SetURL replaces the link to the job's post, empty if it has none.
+// Returns false if the link is unchanged.
+func (j *Job) SetURL(url string) bool {
+	if j.record.GetString("url") == url && j.record.GetBool("unlinkable") == (url == "") {
+		return false
+	}
+	j.record.Set("url", url)
+	j.record.Set("unlinkable", url == "")
+	return true
+}
+
 // Description returns the processed job description.
 func (j *Job) Description() string {
 	return j.record.GetString("description")
 }
 
+// StatusReason returns why the job was rejected or failed.
+func (j *Job) StatusReason() string {
+	return j.record.GetString("statusReason")
+}
+
+// SubIndex returns the position of the job's vacancy within its source
+// message, 0 for the message's own job.
+func (j *Job) SubIndex() int {
+	return j.record.GetInt("subIndex")
+}
+
+// SimHash returns the fingerprint of the original text, false if the job
+// was collected before fingerprints were introduced.
+func (j *Job) SimHash() (SimHash, bool) {
+	h, err := ParseSimHash(j.record.GetString("simhash"))
+	return h, err == nil
+}
+
+// Fingerprint computes the SimHash of the original text.
+func (j *Job) Fingerprint() {
+	j.record.Set("simhash", NewSimHash(j.OriginalText()).String())
+}
+
+// RepostOf returns the job the original text is a near-duplicate of, empty
+// if none. The job is folded into it if both turn out the same vacancy.
+func (j *Job) RepostOf() string {
+	return j.record.GetString("repostOf")
+}
+
+// RepostDistance returns the number of SimHash bits the original text
+// differs by from the job it is a near-duplicate of.
+func (j *Job) RepostDistance() int {
+	return j.record.GetInt("repostDistance")
+}
+
+// SetRepostOf records the job the original text is a near-duplicate of.
+func (j *Job) SetRepostOf(jobID string, distance int) {
+	j.record.Set("repostOf", jobID)
+	j.record.Set("repostDistance", distance)
+}
+
+// Hash returns the normalized hash of the original text.
+func (j *Job) Hash() string {
+	return j.record.GetString("hash")
+}
+
+// Attempts returns how many times processing was started for the job.
+func (j *Job) Attempts() int {
+	return j.record.GetInt("attempts")
+}
+
+// ParsedData reconstructs the currently stored extraction result.
+func (j *Job) ParsedData() ParsedData {
+	var skills []string
+	_ = j.record.UnmarshalJSONField("skills", &skills)
+
+	var contacts []ContactLink
+	_ = j.record.UnmarshalJSONField("contacts", &contacts)
+
+	return ParsedData{
+		IsVacancy:    j.Status() == StatusProcessed,
+		Title:        j.record.GetString("title"),
+		Company:      j.record.GetString("company"),
+		SalaryMin:    j.record.GetInt("salaryMin"),
+		SalaryMax:    j.record.GetInt("salaryMax"),
+		Currency:     j.record.GetString("currency"),
+		SalaryPeriod: SalaryPeriod(j.record.GetString("salaryPeriod")),
+		SalaryTax:    SalaryTax(j.record.GetString("salaryTax")),
+		Skills:       skills,
+		IsRemote:     j.record.GetBool("isRemote"),
+		Grade:        j.record.GetString("grade"),
+		Location:     j.record.GetString("location"),
+		Description:  j.record.GetString("description"),
+		Contacts:     contactsOf(contacts),
+	}
+}
+
+// MessageContacts returns contacts found in the message entities.
+func (j *Job) MessageContacts() []Contact {
+	var contacts []Contact
+	_ = j.record.UnmarshalJSONField("messageContacts", &contacts)
+	return contacts
+}
+
 // Record returns the underlying PocketBase record.
 // Use this when you need to persist changes via app.Save().
 func (j *Job) Record() *core.Record {
@@ -88,11 +267,14 @@ func (j *Job) MarkProcessing() error {
 		return errors.New("can only mark processing from raw state")
 	}
 	j.record.Set("status", string(StatusProcessing))
+	j.record.Set("attempts", j.Attempts()+1)
 	return nil
 }
 
 // Complete transitions job from processing to processed state with parsed data.
-func (j *Job) Complete(data ParsedData) error {
+// Skills are normalized against the taxonomy, contacts found in the message
+// entities are merged with the extracted ones.
+func (j *Job) Complete(data ParsedData, skills *SkillTaxonomy) error {
 	if j.Status() != StatusProcessing {
 		return errors.New("can only complete from processing state")
 	}
@@ -101,24 +283,330 @@ func (j *Job) Complete(data ParsedData) error {
 	j.record.Set("company", data.Company)
 	j.record.Set("salaryMin", data.SalaryMin)
 	j.record.Set("salaryMax", data.SalaryMax)
-	j.record.Set("currency", data.Currency)
+	j.record.Set("currency", NormalizeCurrency(data.Currency))
+	j.record.Set("salaryPeriod", string(data.SalaryPeriod))
+	j.record.Set("salaryTax", string(data.SalaryTax))
 	j.record.Set("grade", data.Grade)
 	j.record.Set("location", data.Location)
 	j.record.Set("isRemote", data.IsRemote)
 	j.record.Set("description", data.Description)
-	j.record.Set("skills", data.Skills)
+	j.record.Set("rawSkills", data.Skills)
+	j.setSkills(data.Skills, skills)
+	j.setContacts(WithoutChannel(MergeContacts(j.MessageContacts(), data.Contacts), j.ChannelUsername()))
 	j.record.Set("status", string(StatusProcessed))
+	j.record.Set("statusReason", "")
+
+	return nil
+}
+
+// ResetToRaw returns a job stuck in processing back to raw so it can be retried.
+func (j *Job) ResetToRaw() error {
+	if j.Status() != StatusProcessing {
+		return errors.New("can only reset to raw from processing state")
+	}
+	j.record.Set("status", string(StatusRaw))
+	return nil
+}
+
+// SetProvenance records how the job's extraction was produced.
+func (j *Job) SetProvenance(p Provenance) {
+	j.record.Set("extractor", p.Extractor)
+	j.record.Set("extractionModel", p.Model)
+	j.record.Set("promptVersion", p.PromptVersion)
+	j.record.Set("promptHash", p.PromptHash)
+	j.record.Set("promptTokens", p.PromptTokens)
+	j.record.Set("completionTokens", p.CompletionTokens)
+	j.record.Set("extractedAt", p.ExtractedAt)
+}
+
+// ResolveSkills maps the extracted skills to canonical ones. The extractor's
+// original list is kept in rawSkills so jobs can be re-resolved after the
+// taxonomy changes.
+func (j *Job) ResolveSkills(skills *SkillTaxonomy) {
+	var raw []string
+	if err := j.record.UnmarshalJSONField("rawSkills", &raw); err != nil || len(raw) == 0 {
+		_ = j.record.UnmarshalJSONField("skills", &raw)
+	}
+
+	j.setSkills(raw, skills)
+}
+
+func (j *Job) setSkills(raw []string, skills *SkillTaxonomy) {
+	names, ids := skills.Resolve(raw)
+	j.record.Set("skills", names)
+	j.record.Set("canonicalSkills", ids)
+}
+
+// setContacts stores contacts along with their deep links.
+func (j *Job) setContacts(contacts []Contact) {
+	links := make([]ContactLink, 0, len(contacts))
+	for _, c := range contacts {
+		links = append(links, ContactLink{Contact: c, Link: c.DeepLink()})
+	}
+	j.record.Set("contacts", links)
+}
+
+func contactsOf(links []ContactLink) []Contact {
+	if len(links) == 0 {
+		return nil
+	}
+	contacts := make([]Contact, 0, len(links))
+	for _, link := range links {
+		contacts = append(contacts, link.Contact)
+	}
+	return contacts
+}
+
+// SetMonthlySalary records the salary range converted to a monthly amount in
+// the base currency. Zero amounts mean the salary could not be normalized.
+func (j *Job) SetMonthlySalary(minSalary, maxSalary int, baseCurrency string) {
+	j.record.Set("salaryMinMonthly", minSalary)
+	j.record.Set("salaryMaxMonthly", maxSalary)
+	j.record.Set("salaryBaseCurrency", baseCurrency)
+}
+
+// SetSalaryPeriod records the period of a salary posted without one.
+func (j *Job) SetSalaryPeriod(period SalaryPeriod) {
+	j.record.Set("salaryPeriod", string(period))
+}
+
+// CompanyID returns the linked company, empty if none.
+func (j *Job) CompanyID() string {
+	return j.record.GetString("employer")
+}
+
+// SetCompany links the job to a company record. The extracted company name
+// stays in the company field as posted.
+func (j *Job) SetCompany(companyID string) {
+	j.record.Set("employer", companyID)
+}
+
+// SetSightings records how often and when the vacancy was seen.
+func (j *Job) SetSightings(stats SightingStats) {
+	j.record.Set("seenCount", stats.Count)
+	j.record.Set("firstSeen", stats.FirstSeen)
+	j.record.Set("lastSeen", stats.LastSeen)
+}
+
+// SetWarnings records sanity check findings of the extraction.
+func (j *Job) SetWarnings(warnings []string) {
+	j.record.Set("extractionWarnings", warnings)
+}
+
+// Reset transitions a finished job (processed, rejected or failed) back to raw
+// so it can be re-extracted, e.g. after a prompt or model change.
+func (j *Job) Reset() error {
+	switch j.Status() {
+	case StatusProcessed, StatusRejected, StatusFailed:
+	default:
+		return errors.New("can only reset from processed, rejected or failed state")
+	}
+	j.record.Set("status", string(StatusRaw))
+	j.record.Set("statusReason", "")
+	return nil
+}
+
+// Reformat replaces the formatting of the original text, returning false if
+// it didn't change.
+func (j *Job) Reformat(input RawJobInput) bool {
+	if j.record.GetString("originalHtml") == input.HTML && j.record.GetString("expandedText") == input.ExpandedText {
+		return false
+	}
+	j.record.Set("originalHtml", input.HTML)
+	j.record.Set("expandedText", input.ExpandedText)
+	return true
+}
+
+// attach stores the documents a vacancy was posted as. The same documents
+// as stored, e.g. on an edit of the caption only, are not stored again.
+func (j *Job) attach(attachments []Attachment) {
+	ids := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		ids = append(ids, attachment.ID)
+	}
+	if slices.Equal(ids, j.Documents().IDs) {
+		return
+	}
+
+	var files []*filesystem.File
+	for _, attachment := range attachments {
+		if len(attachment.Data) == 0 {
+			continue
+		}
+
+		name := attachment.Name
+		if name == "" {
+			name = "attachment"
+		}
+		file, err := filesystem.NewFileFromBytes(attachment.Data, name)
+		if err != nil {
+			continue
+		}
+		files = append(files, file)
+	}
+
+	if len(files) > 0 {
+		j.record.Set("attachments", files)
+		j.record.Set("attachmentIds", ids)
+	}
+}
+
+// AlbumMessageIDs returns the messages of the album the job was posted as
+// that are still posted, nil if it wasn't posted as an album.
+func (j *Job) AlbumMessageIDs() []int {
+	var ids []int
+	_ = j.record.UnmarshalJSONField("albumMessageIds", &ids)
+	return ids
+}
 
+// RemoveAlbumMessages drops deleted messages from the job's album.
+// Returns false if none of them was part of it.
+func (j *Job) RemoveAlbumMessages(messageIDs []int) bool {
+	ids := j.AlbumMessageIDs()
+	kept := slices.DeleteFunc(slices.Clone(ids), func(id int) bool {
+		return slices.Contains(messageIDs, id)
+	})
+	if len(kept) == len(ids) {
+		return false
+	}
+	j.record.Set("albumMessageIds", kept)
+	return true
+}
+
+// Documents returns the ids of the stored attachments and the text read
+// from them.
+func (j *Job) Documents() Documents {
+	var ids []string
+	_ = j.record.UnmarshalJSONField("attachmentIds", &ids)
+	return Documents{IDs: ids, Text: j.record.GetString("documentText")}
+}
+
+// Edit replaces the original text with an edited one. Raw jobs get a new
+// preliminary title; others keep their extraction until re-extracted.
+func (j *Job) Edit(input RawJobInput, editedAt time.Time) {
+	j.record.Set("originalText", input.OriginalText)
+	j.record.Set("originalHtml", input.HTML)
+	j.record.Set("expandedText", input.ExpandedText)
+	j.record.Set("hash", input.Hash)
+	j.record.Set("simhash", NewSimHash(input.OriginalText).String())
+	j.record.Set("messageContacts", MergeContacts(input.Contacts))
+	j.record.Set("editedAt", editedAt)
+	if input.RawData != nil {
+		j.record.Set("raw", input.RawData)
+	}
+	j.record.Set("documentText", input.DocumentText)
+	j.attach(input.Attachments)
+
+	// An album merged again, possibly without the part the job links to
+	if input.AlbumID != 0 {
+		j.record.Set("albumMessageIds", input.AlbumMessageIDs)
+		j.SetURL(input.URL)
+	}
+
+	if j.Status() == StatusRaw {
+		j.record.Set("title", previewTitle(input.OriginalText))
+	}
+}
+
+// Close transitions a raw, processed or failed job to closed state.
+// Used when the poster marks the vacancy as taken.
+func (j *Job) Close(closedAt time.Time) error {
+	switch j.Status() {
+	case StatusRaw, StatusProcessed, StatusFailed:
+	default:
+		return errors.New("can only close from raw, processed or failed state")
+	}
+	j.record.Set("status", string(StatusClosed))
+	j.record.Set("statusReason", "closed by the poster")
+	j.record.Set("closedAt", closedAt)
+	return nil
+}
+
+// Reopen transitions a closed job back to raw so it is extracted again.
+func (j *Job) Reopen() error {
+	if j.Status() != StatusClosed {
+		return errors.New("can only reopen from closed state")
+	}
+	j.record.Set("status", string(StatusRaw))
+	j.record.Set("statusReason", "")
+	j.record.Set("closedAt", "")
 	return nil
 }
 
+// Withdraw transitions a raw, processed or failed job to withdrawn state.
+// Used when the posts of the vacancy were deleted; the record is kept.
+func (j *Job) Withdraw(withdrawnAt time.Time) error {
+	switch j.Status() {
+	case StatusRaw, StatusProcessed, StatusFailed:
+	default:
+		return errors.New("can only withdraw from raw, processed or failed state")
+	}
+	j.record.Set("withdrawnFrom", string(j.Status()))
+	j.record.Set("status", string(StatusWithdrawn))
+	j.record.Set("statusReason", "post deleted")
+	j.record.Set("withdrawnAt", withdrawnAt)
+	return nil
+}
+
+// Restore transitions a withdrawn job back to the status it was withdrawn
+// from, raw if unknown. Used when the vacancy is posted again; an extracted
+// job stays extracted.
+func (j *Job) Restore() error {
+	if j.Status() != StatusWithdrawn {
+		return errors.New("can only restore from withdrawn state")
+	}
+	status := JobStatus(j.record.GetString("withdrawnFrom"))
+	if status == "" {
+		status = StatusRaw
+	}
+	j.record.Set("status", string(status))
+	j.record.Set("statusReason", "")
+	j.record.Set("withdrawnAt", "")
+	j.record.Set("withdrawnFrom", "")
+	return nil
+}
+
+// IsAvailable returns false for jobs closed or withdrawn by their poster.
+func (j *Job) IsAvailable() bool {
+	switch j.Status() {
+	case StatusClosed, StatusWithdrawn:
+		return false
+	}
+	return true
+}
+
 // Reject transitions job from processing to rejected state.
+// Used when the posting is not a vacancy; rejected jobs are final.
 func (j *Job) Reject(reason string) error {
 	if j.Status() != StatusProcessing {
 		return errors.New("can only reject from processing state")
 	}
 	j.record.Set("status", string(StatusRejected))
-	// Optionally store rejection reason if field exists
+	j.record.Set("statusReason", reason)
+	return nil
+}
+
+// Fail transitions job from processing to failed state.
+// Used for transient errors (LLM/network); failed jobs can be retried.
+func (j *Job) Fail(reason string, cause error) error {
+	if j.Status() != StatusProcessing {
+		return errors.New("can only fail from processing state")
+	}
+	j.record.Set("status", string(StatusFailed))
+	j.record.Set("statusReason", reason)
+	if cause != nil {
+		j.record.Set("lastError", cause.Error())
+	}
+	return nil
+}
+
+// Retry transitions job from failed back to raw state.
+func (j *Job) Retry() error {
+	if j.Status() != StatusFailed {
+		return errors.New("can only retry from failed state")
+	}
+	j.record.Set("status", string(StatusRaw))
+	j.record.Set("statusReason", "")
 	return nil
 }
 
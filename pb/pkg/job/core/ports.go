@@ -1,6 +1,9 @@
 package core
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // JobStatus represents the processing state of a job.
 type JobStatus string
@@ -10,30 +13,126 @@ const (
 	StatusProcessing JobStatus = "processing"
 	StatusProcessed  JobStatus = "processed"
 	StatusRejected   JobStatus = "rejected"
+	StatusFailed     JobStatus = "failed"
+	// StatusClosed is a vacancy its poster marked as taken
+	StatusClosed JobStatus = "closed"
+	// StatusWithdrawn is a vacancy whose posts were all deleted
+	StatusWithdrawn JobStatus = "withdrawn"
 )
 
+// PostedStatuses are the statuses of jobs extracted as vacancies, whether
+// still open or not. Used for posting history.
+var PostedStatuses = []JobStatus{StatusProcessed, StatusClosed, StatusWithdrawn}
+
 // RawJobInput contains data needed to create a new raw job.
 type RawJobInput struct {
 	OriginalText string
+	// HTML is the message as shown in Telegram, with formatting and links.
+	// It is rendered from escaped text and safe to display.
+	HTML string
+	// ExpandedText is the text with hidden links written out, extracted from
+	// instead of the plain text.
+	ExpandedText string
 	ChannelID    int64
 	MessageID    int
-	Hash         string
-	RawData      any
+	// URL links to the message, empty if its chat can't be linked
+	URL     string
+	Hash    string
+	RawData any
+	// PostedAt is when the message was posted, zero if unknown
+	PostedAt time.Time
+	// EditedAt is when the message was last edited, zero if never
+	EditedAt time.Time
+	// Contacts found in message entities: mentions, links, emails
+	Contacts []Contact
+	// Priority of the source, higher is extracted first
+	Priority int
+	// Attachments are the documents the vacancy was posted as
+	Attachments []Attachment
+	// DocumentText is the text read from Attachments, included in
+	// OriginalText
+	DocumentText string
+	// AlbumID groups the messages of an album the post was sent as, 0 if
+	// not one; AlbumMessageIDs are its messages
+	AlbumID         int64
+	AlbumMessageIDs []int
+}
+
+// Attachment is a document attached to a post, stored with its job.
+type Attachment struct {
+	// ID identifies the document in Telegram
+	ID   string
+	Name string
+	// Data is nil for a document kept from the stored job, see Documents
+	Data []byte
+}
+
+// Documents are the documents stored with a job and the text read from
+// them.
+type Documents struct {
+	IDs  []string
+	Text string
+}
+
+// SubmitResult reports what SubmitRaw did with a message.
+type SubmitResult struct {
+	// JobID is the new job, or the existing one the message belongs to
+	JobID string
+	// Created is true if a new job was created
+	Created bool
+	// Repost is true if the message was recorded as a new sighting of JobID.
+	// Neither is set for a message that was collected before.
+	Repost bool
 }
 
 // ParsedData represents structured output from LLM extraction.
 type ParsedData struct {
-	IsVacancy   bool     `json:"isVacancy"`
-	Title       string   `json:"title"`
-	Company     string   `json:"company"`
-	SalaryMin   int      `json:"salaryMin"`
-	SalaryMax   int      `json:"salaryMax"`
-	Currency    string   `json:"currency"`
-	Skills      []string `json:"skills"`
-	IsRemote    bool     `json:"isRemote"`
-	Grade       string   `json:"grade"`
-	Location    string   `json:"location"`
-	Description string   `json:"description"`
+	IsVacancy    bool         `json:"isVacancy"`
+	Title        string       `json:"title"`
+	Company      string       `json:"company"`
+	SalaryMin    int          `json:"salaryMin"`
+	SalaryMax    int          `json:"salaryMax"`
+	Currency     string       `json:"currency" description:"ISO 4217 code, e.g. USD, EUR, RUB"`
+	SalaryPeriod SalaryPeriod `json:"salaryPeriod" enum:"hour,month,year,"`
+	SalaryTax    SalaryTax    `json:"salaryTax" enum:"gross,net,"`
+	Skills       []string     `json:"skills"`
+	IsRemote     bool         `json:"isRemote"`
+	Grade        string       `json:"grade"`
+	Location     string       `json:"location"`
+	Description  string       `json:"description"`
+	Contacts     []Contact    `json:"contacts"`
+}
+
+// Provenance records which extractor, model and prompt produced a ParsedData.
+type Provenance struct {
+	Extractor        string    `json:"extractor"`
+	Model            string    `json:"model"`
+	PromptVersion    string    `json:"promptVersion"`
+	PromptHash       string    `json:"promptHash"`
+	PromptTokens     int       `json:"promptTokens"`
+	CompletionTokens int       `json:"completionTokens"`
+	ExtractedAt      time.Time `json:"extractedAt"`
+}
+
+// Extraction is the result of a JobExtractor run.
+type Extraction struct {
+	Data ParsedData
+	// Siblings holds further vacancies of a post listing several; Data is the first.
+	Siblings   []ParsedData
+	Provenance Provenance
+	// Warnings lists sanity check findings, see SanityCheck.
+	Warnings []string
+}
+
+// Vacancy returns the vacancy with the given index within the post, 0 being Data.
+func (e Extraction) Vacancy(subIndex int) (ParsedData, bool) {
+	if subIndex == 0 {
+		return e.Data, true
+	}
+	if subIndex < 0 || subIndex > len(e.Siblings) {
+		return ParsedData{}, false
+	}
+	return e.Siblings[subIndex-1], true
 }
 
 // --- Service Interface (driving port) ---
@@ -41,24 +140,176 @@ type ParsedData struct {
 // JobService is the main interface for job module operations.
 // Used by collector module and adapters/in.
 type JobService interface {
-	// SubmitRaw creates a new job in raw state. Returns job ID.
-	SubmitRaw(ctx context.Context, input RawJobInput) (string, error)
+	// SubmitRaw creates a new job in raw state. A repost of an already
+	// collected text, exact or with minor edits, is recorded as a sighting
+	// of the existing job instead. Submitting a message again, even
+	// concurrently, returns the job it was collected as.
+	SubmitRaw(ctx context.Context, input RawJobInput) (SubmitResult, error)
 
-	// Process runs LLM extraction on a raw job.
+	// Edit applies an edited message to the job it was collected as: the
+	// previous text is kept as a revision and the job is re-extracted, or
+	// closed if the edit marks the vacancy as taken. Messages never
+	// collected, or only as a repost, are left alone.
+	Edit(ctx context.Context, input RawJobInput) (EditResult, error)
+
+	// Documents returns the documents stored with the job of a message, so
+	// that an edit keeping them doesn't read them again. Zero if the message
+	// has no job.
+	Documents(ctx context.Context, channelID int64, messageID int) (Documents, error)
+
+	// Albums returns the messages of the collected albums the given
+	// messages of a channel are part of, one list per album.
+	Albums(ctx context.Context, channelID int64, messageIDs []int) ([][]int, error)
+
+	// Withdraw handles deleted messages of a channel. Jobs none of whose
+	// posts remain are withdrawn, albums once all their messages are
+	// deleted; a later repost restores them.
+	// Returns the number of jobs withdrawn.
+	Withdraw(ctx context.Context, channelID int64, messageIDs []int) (int, error)
+
+	// Relink rebuilds the links of the jobs and sightings of a chat, e.g.
+	// once its username is known. postURL returns the link of a message,
+	// false if it has none. Returns the number of jobs updated.
+	Relink(ctx context.Context, channelID int64, postURL func(messageID int) (string, bool)) (int, error)
+
+	// Sightings returns where and when a job was posted, oldest first.
+	Sightings(ctx context.Context, jobID string) ([]Sighting, error)
+
+	// Process runs LLM extraction on a raw or failed job. Returns
+	// ErrNotProcessable for jobs in any other state.
 	Process(ctx context.Context, jobID string) error
 
+	// Retry moves a failed job back to raw so it gets processed again.
+	Retry(ctx context.Context, jobID string) error
+
+	// FindForReprocess returns ids of jobs matching the filter.
+	FindForReprocess(ctx context.Context, filter ReprocessFilter) ([]string, error)
+
+	// Reprocess resets the given jobs to raw and re-extracts them.
+	// progress, if not nil, is called after each job.
+	Reprocess(ctx context.Context, jobIDs []string, opts ReprocessOptions, progress func(ReprocessResult)) (ReprocessReport, error)
+
 	// GenerateOffer creates a personalized offer message for a job.
 	GenerateOffer(ctx context.Context, jobID, userID string) (string, error)
 
-	// CheckDuplicate returns true if a job with same channelID/messageID or hash exists.
-	CheckDuplicate(ctx context.Context, channelID int64, messageID int, hash string) (bool, error)
+	// NormalizeSalaries recomputes monthly base currency salaries of processed
+	// jobs, e.g. after exchange rates change. An empty currency means all jobs.
+	// Returns the number of jobs updated.
+	NormalizeSalaries(ctx context.Context, currency string) (int, error)
+
+	// Fingerprint computes SimHash fingerprints of jobs collected without
+	// one. Returns the number of jobs updated.
+	Fingerprint(ctx context.Context) (int, error)
+}
+
+// JobQueue is a persistent queue of jobs awaiting LLM processing.
+// Used by hooks to schedule work and by the worker pool to drain it.
+type JobQueue interface {
+	// Enqueue schedules a job for processing. Enqueuing an already queued job is a no-op.
+	Enqueue(ctx context.Context, jobID string) error
+
+	// Lease claims the next due entry. Returns nil when nothing is due.
+	Lease(ctx context.Context) (*QueueItem, error)
+
+	// Ack removes a successfully processed entry.
+	Ack(ctx context.Context, item QueueItem) error
+
+	// Nack schedules a retry with backoff or buries the entry after max attempts.
+	Nack(ctx context.Context, item QueueItem, cause error) error
+
+	// Sweep re-queues jobs left in raw or processing state. Returns the number re-queued.
+	Sweep(ctx context.Context) (int, error)
+}
+
+// ExchangeRates is the locally maintained exchange rate table.
+type ExchangeRates interface {
+	// Convert converts an amount between currencies.
+	// Returns ErrUnknownCurrency if either rate is missing.
+	Convert(ctx context.Context, amount float64, from, to string) (float64, error)
+
+	// List returns all known rates.
+	List(ctx context.Context) ([]ExchangeRate, error)
+
+	// Set creates or updates the rate of a currency.
+	Set(ctx context.Context, currency string, perUSD float64) error
+}
+
+// SkillService manages the canonical skills taxonomy.
+type SkillService interface {
+	// Taxonomy returns the current taxonomy. It is cached until Invalidate.
+	Taxonomy(ctx context.Context) (*SkillTaxonomy, error)
+
+	// Invalidate drops the cached taxonomy after skills change.
+	Invalidate()
+
+	// List returns all canonical skills.
+	List(ctx context.Context) ([]Skill, error)
+
+	// Add creates a skill or adds aliases to an existing one with the same name.
+	Add(ctx context.Context, skill Skill) error
+
+	// Merge folds sources into target: their names and aliases become aliases
+	// of target and the source skills are deleted. Returns jobs relinked.
+	Merge(ctx context.Context, target string, sources []string) (int, error)
+
+	// Split moves aliases of source to into, creating it if needed.
+	// Returns jobs relinked.
+	Split(ctx context.Context, source string, aliases []string, into Skill) (int, error)
+
+	// Relink re-resolves skills of all processed jobs. Returns jobs updated.
+	Relink(ctx context.Context) (int, error)
+
+	// Facets counts processed jobs per canonical skill.
+	Facets(ctx context.Context) ([]SkillFacet, error)
+}
+
+// CompanyService manages deduplicated employers.
+type CompanyService interface {
+	// Resolve finds the company matching company.Name by key or alias, or
+	// creates it. Contacts and websites are added to the company.
+	// Returns an empty id for an empty name.
+	Resolve(ctx context.Context, company Company) (string, error)
+
+	// Get returns a company by id.
+	Get(ctx context.Context, id string) (Company, error)
+
+	// Search returns companies whose name or aliases contain query.
+	Search(ctx context.Context, query string, limit int) ([]Company, error)
+
+	// Add creates a company or adds aliases, contacts and websites to the
+	// one with the same name.
+	Add(ctx context.Context, company Company) error
+
+	// Merge folds sources into target and moves their jobs. Returns jobs moved.
+	Merge(ctx context.Context, target string, sources []string) (int, error)
+
+	// Relink links processed jobs without a company. Returns jobs linked.
+	Relink(ctx context.Context) (int, error)
+
+	// Postings returns the jobs of a company counted by Stats, newest first.
+	Postings(ctx context.Context, id string) ([]CompanyPosting, error)
+
+	// Stats aggregates postings and salaries of a company per "week" or "month".
+	Stats(ctx context.Context, id, interval string) (CompanyStats, error)
 }
 
 // --- Driven Ports (implemented in adapters/out) ---
 
 // JobExtractor extracts structured data from job posting text.
 type JobExtractor interface {
-	Extract(ctx context.Context, text string) (ParsedData, error)
+	Extract(ctx context.Context, text string) (Extraction, error)
+}
+
+// ExtractionCache is a JobExtractor decorator that reuses previous results
+// for identical input text.
+type ExtractionCache interface {
+	JobExtractor
+
+	// Stats returns hit/miss counters and estimated savings.
+	Stats(ctx context.Context) (ExtractionCacheStats, error)
+
+	// Purge removes expired entries. Returns the number removed.
+	Purge(ctx context.Context) (int, error)
 }
 
 // OfferGenerator generates personalized offer messages.
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// QueueStatus represents the state of a job queue entry.
+type QueueStatus string
+
+const (
+	QueuePending QueueStatus = "pending"
+	QueueLeased  QueueStatus = "leased"
+	QueueDead    QueueStatus = "dead"
+)
+
+// QueueItem is a leased entry handed out to a worker.
+type QueueItem struct {
+	ID      string
+	JobID   string
+	Attempt int
+}
+
+// QueueEntry wraps a jobQueue record and provides state machine methods.
+type QueueEntry struct {
+	record *core.Record
+}
+
+// NewQueueEntry creates a QueueEntry from an existing PocketBase record.
+func NewQueueEntry(record *core.Record) *QueueEntry {
+	return &QueueEntry{record: record}
+}
+
+// NewPendingQueueEntry creates a new pending entry for the given job. Entries
+// of higher priority are leased first.
+func NewPendingQueueEntry(collection *core.Collection, jobID string, priority int) *QueueEntry {
+	record := core.NewRecord(collection)
+	record.Set("job", jobID)
+	record.Set("priority", priority)
+	record.Set("status", string(QueuePending))
+	record.Set("attempts", 0)
+	record.Set("runAt", types.NowDateTime())
+
+	return &QueueEntry{record: record}
+}
+
+// --- Getters ---
+
+// ID returns the entry's unique identifier.
+func (q *QueueEntry) ID() string {
+	return q.record.Id
+}
+
+// JobID returns the id of the queued job.
+func (q *QueueEntry) JobID() string {
+	return q.record.GetString("job")
+}
+
+// Status returns the current entry status.
+func (q *QueueEntry) Status() QueueStatus {
+	return QueueStatus(q.record.GetString("status"))
+}
+
+// Attempts returns how many times the entry has been leased.
+func (q *QueueEntry) Attempts() int {
+	return q.record.GetInt("attempts")
+}
+
+// Item returns the worker-facing view of the entry.
+func (q *QueueEntry) Item() QueueItem {
+	return QueueItem{
+		ID:      q.ID(),
+		JobID:   q.JobID(),
+		Attempt: q.Attempts(),
+	}
+}
+
+// Record returns the underlying PocketBase record.
+func (q *QueueEntry) Record() *core.Record {
+	return q.record
+}
+
+// --- State Machine Methods ---
+
+// Lease hands the entry to a worker until the given deadline.
+// Expired leases may be taken over by another worker.
+func (q *QueueEntry) Lease(until time.Time) error {
+	if q.Status() == QueueDead {
+		return errors.New("cannot lease a dead entry")
+	}
+	q.record.Set("status", string(QueueLeased))
+	q.record.Set("attempts", q.Attempts()+1)
+	q.record.Set("leasedUntil", until)
+	return nil
+}
+
+// Retry returns a leased entry to pending, to be picked up again at runAt.
+func (q *QueueEntry) Retry(runAt time.Time, cause string) error {
+	if q.Status() != QueueLeased {
+		return errors.New("can only retry a leased entry")
+	}
+	q.record.Set("status", string(QueuePending))
+	q.record.Set("runAt", runAt)
+	q.record.Set("leasedUntil", nil)
+	q.record.Set("lastError", cause)
+	return nil
+}
+
+// Bury moves a leased entry to the dead state after exhausting its attempts.
+func (q *QueueEntry) Bury(cause string) error {
+	if q.Status() != QueueLeased {
+		return errors.New("can only bury a leased entry")
+	}
+	q.record.Set("status", string(QueueDead))
+	q.record.Set("leasedUntil", nil)
+	q.record.Set("lastError", cause)
+	return nil
+}
+
+// Release returns an entry leased by a stopped process to pending. Unlike
+// Revive it keeps the attempts already spent.
+func (q *QueueEntry) Release() {
+	q.record.Set("status", string(QueuePending))
+	q.record.Set("runAt", types.NowDateTime())
+	q.record.Set("leasedUntil", nil)
+}
+
+// Revive resets a dead entry to pending with a fresh attempt budget.
+func (q *QueueEntry) Revive() {
+	q.record.Set("status", string(QueuePending))
+	q.record.Set("attempts", 0)
+	q.record.Set("runAt", types.NowDateTime())
+	q.record.Set("leasedUntil", nil)
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ReprocessFilter selects jobs for bulk re-extraction.
+// Zero values are ignored.
+type ReprocessFilter struct {
+	Statuses  []JobStatus
+	From      time.Time
+	To        time.Time
+	ChannelID string
+	// Expr is an additional PocketBase filter expression, e.g. `grade = ""`.
+	Expr  string
+	Limit int
+}
+
+// DryRunLimit caps dry runs selected without a limit. They extract while
+// the caller waits and only preview a few jobs.
+const DryRunLimit = 20
+
+// ReprocessOptions controls how a bulk re-extraction runs.
+type ReprocessOptions struct {
+	Concurrency int
+	// DryRun extracts and diffs without saving anything.
+	DryRun bool
+}
+
+// FieldChange is a single ParsedData field that differs after re-extraction.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// ReprocessResult is the outcome of re-extracting one job.
+type ReprocessResult struct {
+	JobID   string        `json:"jobId"`
+	Status  JobStatus     `json:"status"`
+	Changes []FieldChange `json:"changes"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// ReprocessReport summarizes a bulk re-extraction run.
+type ReprocessReport struct {
+	Matched int               `json:"matched"`
+	Done    int               `json:"done"`
+	Errors  int               `json:"errors"`
+	DryRun  bool              `json:"dryRun"`
+	Results []ReprocessResult `json:"results"`
+}
+
+// DiffParsedData returns the fields that differ between two extractions.
+func DiffParsedData(before, after ParsedData) []FieldChange {
+	var changes []FieldChange
+
+	bv := reflect.ValueOf(before)
+	av := reflect.ValueOf(after)
+	t := bv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		oldVal, newVal := bv.Field(i), av.Field(i)
+		if reflect.DeepEqual(oldVal.Interface(), newVal.Interface()) || (isBlank(oldVal) && isBlank(newVal)) {
+			continue
+		}
+
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = t.Field(i).Name
+		}
+
+		changes = append(changes, FieldChange{Field: name, Old: oldVal.Interface(), New: newVal.Interface()})
+	}
+
+	return changes
+}
+
+// isBlank treats nil and empty slices as equal.
+func isBlank(v reflect.Value) bool {
+	return v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0)
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// SalaryPeriod is the time unit a salary is quoted in.
+type SalaryPeriod string
+
+const (
+	PeriodHour  SalaryPeriod = "hour"
+	PeriodMonth SalaryPeriod = "month"
+	PeriodYear  SalaryPeriod = "year"
+)
+
+// SalaryTax tells whether a salary is quoted before or after taxes.
+type SalaryTax string
+
+const (
+	TaxGross SalaryTax = "gross"
+	TaxNet   SalaryTax = "net"
+)
+
+// hoursPerMonth is a full-time month (40h * ~4 weeks).
+const hoursPerMonth = 160
+
+// maxMonthlyUSD is the largest amount, in US dollars, taken for a monthly
+// salary when a post names no period. Larger ones are yearly.
+const maxMonthlyUSD = 20000
+
+// ErrUnknownCurrency is returned when no exchange rate is known for a currency.
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// ExchangeRate is the price of one US dollar in a currency.
+// Rates are stored against USD so the base currency can change freely.
+type ExchangeRate struct {
+	Currency string    `json:"currency"`
+	PerUSD   float64   `json:"perUsd"`
+	Updated  time.Time `json:"updated"`
+}
+
+// currencyAliases maps symbols and common spellings to ISO 4217 codes.
+var currencyAliases = map[string]string{
+	"$": "USD", "US$": "USD", "ДОЛЛ": "USD", "ДОЛЛАР": "USD", "ДОЛЛАРОВ": "USD",
+	"€": "EUR", "ЕВРО": "EUR",
+	"₽": "RUB", "РУБ": "RUB", "РУБЛЕЙ": "RUB", "Р": "RUB", "RUR": "RUB",
+	"£": "GBP",
+	"₸": "KZT", "ТЕНГЕ": "KZT",
+	"₴": "UAH", "ГРН": "UAH",
+}
+
+// NormalizeCurrency converts a currency symbol or name to its ISO 4217 code.
+// Unknown values are returned upper-cased.
+func NormalizeCurrency(currency string) string {
+	c := strings.ToUpper(strings.Trim(strings.TrimSpace(currency), "."))
+	if code, ok := currencyAliases[c]; ok {
+		return code
+	}
+	return c
+}
+
+// MonthlySalary converts an amount quoted per period to a monthly amount.
+// Unknown periods are treated as monthly, the most common case in job posts.
+func MonthlySalary(amount int, period SalaryPeriod) float64 {
+	switch period {
+	case PeriodHour:
+		return float64(amount) * hoursPerMonth
+	case PeriodYear:
+		return float64(amount) / 12
+	default:
+		return float64(amount)
+	}
+}
+
+// InferSalaryPeriod returns the period of a salary posted without one, given
+// its largest amount in US dollars: yearly if too large for a month, unknown
+// otherwise.
+func InferSalaryPeriod(amountUSD float64) SalaryPeriod {
+	if amountUSD > maxMonthlyUSD {
+		return PeriodYear
+	}
+	return ""
+}
+
+// SalaryWithTax converts an amount quoted gross or net to the given basis
+// with a flat tax rate. Amounts quoted without a marker are taken as is.
+func SalaryWithTax(amount float64, quoted, basis SalaryTax, rate float64) float64 {
+	if quoted == "" || basis == "" || quoted == basis || rate <= 0 || rate >= 1 {
+		return amount
+	}
+	if basis == TaxNet {
+		return amount * (1 - rate)
+	}
+	return amount / (1 - rate)
+}
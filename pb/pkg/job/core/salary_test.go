@@ -0,0 +1,64 @@
+package core
+
+import "testing"
+
+func TestInferSalaryPeriod(t *testing.T) {
+	tests := []struct {
+		amountUSD float64
+		want      SalaryPeriod
+	}{
+		{0, ""},
+		{4000, ""},
+		{maxMonthlyUSD, ""},
+		{maxMonthlyUSD + 1, PeriodYear},
+		{120000, PeriodYear},
+	}
+
+	for _, tt := range tests {
+		if got := InferSalaryPeriod(tt.amountUSD); got != tt.want {
+			t.Errorf("InferSalaryPeriod(%v) = %q, want %q", tt.amountUSD, got, tt.want)
+		}
+	}
+}
+
+func TestMonthlySalary(t *testing.T) {
+	tests := []struct {
+		amount int
+		period SalaryPeriod
+		want   float64
+	}{
+		{50, PeriodHour, 50 * hoursPerMonth},
+		{5000, PeriodMonth, 5000},
+		{120000, PeriodYear, 10000},
+		{5000, "", 5000},
+	}
+
+	for _, tt := range tests {
+		if got := MonthlySalary(tt.amount, tt.period); got != tt.want {
+			t.Errorf("MonthlySalary(%d, %q) = %v, want %v", tt.amount, tt.period, got, tt.want)
+		}
+	}
+}
+
+func TestSalaryWithTax(t *testing.T) {
+	tests := []struct {
+		name          string
+		amount        float64
+		quoted, basis SalaryTax
+		want          float64
+	}{
+		{"gross to net", 1000, TaxGross, TaxNet, 870},
+		{"net to gross", 870, TaxNet, TaxGross, 1000},
+		{"same basis", 1000, TaxNet, TaxNet, 1000},
+		{"quoted without marker", 1000, "", TaxNet, 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SalaryWithTax(tt.amount, tt.quoted, tt.basis, 0.13)
+			if got < tt.want-0.01 || got > tt.want+0.01 {
+				t.Errorf("SalaryWithTax(%v, %q, %q) = %v, want %v", tt.amount, tt.quoted, tt.basis, got, tt.want)
+			}
+		})
+	}
+}
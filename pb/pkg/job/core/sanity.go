@@ -0,0 +1,65 @@
+package core
+
+import "fmt"
+
+// salaryTolerance is how far apart LLM and pre-fill salaries may be before
+// they are reported, as a fraction of the larger value.
+const salaryTolerance = 0.2
+
+// SanityCheck compares an LLM extraction with a deterministic pre-fill of the
+// same text and returns findings that suggest the LLM got it wrong.
+func SanityCheck(llm, prefill ParsedData) []string {
+	var warnings []string
+
+	prefillSalary := prefill.SalaryMin > 0 || prefill.SalaryMax > 0
+	llmSalary := llm.SalaryMin > 0 || llm.SalaryMax > 0
+
+	if !llm.IsVacancy {
+		if prefill.IsVacancy && prefillSalary {
+			warnings = append(warnings, fmt.Sprintf("rejected, but pre-fill found vacancy %q with salary", prefill.Title))
+		}
+		return warnings
+	}
+
+	if llm.Title == "" {
+		warnings = append(warnings, "vacancy without title")
+	}
+
+	if llm.SalaryMin > 0 && llm.SalaryMax > 0 && llm.SalaryMin > llm.SalaryMax {
+		warnings = append(warnings, fmt.Sprintf("salaryMin %d is greater than salaryMax %d", llm.SalaryMin, llm.SalaryMax))
+	}
+
+	switch {
+	case prefillSalary && !llmSalary:
+		warnings = append(warnings, fmt.Sprintf("salary missing, pre-fill found %d-%d %s",
+			prefill.SalaryMin, prefill.SalaryMax, prefill.Currency))
+	case prefillSalary && llmSalary:
+		if !salaryClose(llm.SalaryMin, prefill.SalaryMin) || !salaryClose(llm.SalaryMax, prefill.SalaryMax) {
+			warnings = append(warnings, fmt.Sprintf("salary %d-%d differs from pre-fill %d-%d",
+				llm.SalaryMin, llm.SalaryMax, prefill.SalaryMin, prefill.SalaryMax))
+		}
+	}
+
+	if llm.Currency != "" && prefill.Currency != "" && NormalizeCurrency(llm.Currency) != prefill.Currency {
+		warnings = append(warnings, fmt.Sprintf("currency %s differs from pre-fill %s", llm.Currency, prefill.Currency))
+	}
+
+	if llm.SalaryPeriod != "" && prefill.SalaryPeriod != "" && llm.SalaryPeriod != prefill.SalaryPeriod {
+		warnings = append(warnings, fmt.Sprintf("salary period %s differs from pre-fill %s", llm.SalaryPeriod, prefill.SalaryPeriod))
+	}
+
+	return warnings
+}
+
+// salaryClose reports whether two amounts agree. Zero means unknown and
+// agrees with anything.
+func salaryClose(a, b int) bool {
+	if a == 0 || b == 0 {
+		return true
+	}
+	diff := float64(a - b)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= salaryTolerance*float64(max(a, b))
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Sighting is one appearance of a vacancy in a channel. Reposts of the same
+// text are recorded as sightings of the job collected first.
+type Sighting struct {
+	ID        string         `db:"id" json:"id"`
+	JobID     string         `db:"job" json:"job"`
+	ChannelID string         `db:"channelId" json:"channelId"`
+	MessageID int            `db:"messageId" json:"messageId"`
+	URL       string         `db:"url" json:"url"`
+	SeenAt    types.DateTime `db:"seenAt" json:"seenAt"`
+	// Distance is the number of differing SimHash bits, 0 for the same text
+	Distance int `db:"distance" json:"distance"`
+	// DeletedAt is when the message was deleted, zero while it is posted
+	DeletedAt types.DateTime `db:"deletedAt" json:"deletedAt"`
+}
+
+// SightingStats summarizes the sightings of a job.
+type SightingStats struct {
+	Count     int            `db:"count"`
+	FirstSeen types.DateTime `db:"firstSeen"`
+	LastSeen  types.DateTime `db:"lastSeen"`
+}
+
+// SameVacancy reports whether two extractions describe the same vacancy.
+// A near-duplicate text is a repost only if its title, company and salary
+// agree with the job it resembles: template posts differ in little else.
+func SameVacancy(a, b ParsedData) bool {
+	return a.IsVacancy && b.IsVacancy &&
+		sameField(a.Title, b.Title) &&
+		sameField(a.Company, b.Company) &&
+		a.SalaryMin == b.SalaryMin &&
+		a.SalaryMax == b.SalaryMax &&
+		NormalizeCurrency(a.Currency) == NormalizeCurrency(b.Currency)
+}
+
+// sameField compares extracted values ignoring case and spacing.
+func sameField(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
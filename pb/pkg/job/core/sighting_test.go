@@ -0,0 +1,33 @@
+package core
+
+import "testing"
+
+func TestSameVacancy(t *testing.T) {
+	base := ParsedData{IsVacancy: true, Title: "Senior Go Developer", Company: "Acme", SalaryMin: 5000, SalaryMax: 6000, Currency: "USD"}
+
+	tests := []struct {
+		name   string
+		change func(*ParsedData)
+		want   bool
+	}{
+		{"same", func(*ParsedData) {}, true},
+		{"case and spacing", func(d *ParsedData) { d.Title = "senior  go developer " }, true},
+		{"currency symbol", func(d *ParsedData) { d.Currency = "$" }, true},
+		{"skills differ", func(d *ParsedData) { d.Skills = []string{"Go"} }, true},
+		{"other title", func(d *ParsedData) { d.Title = "Middle Go Developer" }, false},
+		{"other company", func(d *ParsedData) { d.Company = "Globex" }, false},
+		{"other salary", func(d *ParsedData) { d.SalaryMax = 7000 }, false},
+		{"other currency", func(d *ParsedData) { d.Currency = "EUR" }, false},
+		{"not a vacancy", func(d *ParsedData) { d.IsVacancy = false }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base
+			tt.change(&other)
+			if got := SameVacancy(base, other); got != tt.want {
+				t.Errorf("SameVacancy = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
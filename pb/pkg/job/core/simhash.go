@@ -0,0 +1,103 @@
+package core
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// shingleSize is the number of words per shingle.
+	shingleSize = 3
+
+	// SimHashBands is the number of 8-bit bands a fingerprint is indexed
+	// by. Fingerprints within SimHashBands-1 bits share at least one band.
+	SimHashBands = 8
+
+	// minShingles is the number of shingles a text needs for its fingerprint
+	// to tell it apart from other texts. Emoji only or a one-liner say too
+	// little and all fingerprint alike.
+	minShingles = 8
+)
+
+// SimHash is a 64-bit locality sensitive fingerprint of a text: texts
+// differing in a few words have fingerprints differing in a few bits.
+type SimHash uint64
+
+// NewSimHash fingerprints text by its word shingles. Case, punctuation and
+// emoji are ignored, so reposts with a changed emoji or an added line differ
+// by a few bits only.
+func NewSimHash(text string) SimHash {
+	words := simHashWords(text)
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	add := func(shingle []string) {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(shingle, " ")))
+		sum := h.Sum64()
+		for i := range weights {
+			if sum&(1<<i) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	if len(words) < shingleSize {
+		add(words)
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		add(words[i : i+shingleSize])
+	}
+
+	var hash SimHash
+	for i, w := range weights {
+		if w > 0 {
+			hash |= 1 << i
+		}
+	}
+	return hash
+}
+
+// NearMatchable reports whether a text is long enough to be matched with
+// others by its fingerprint. Shorter texts are only matched exactly.
+func NearMatchable(text string) bool {
+	return len(simHashWords(text))-shingleSize+1 >= minShingles
+}
+
+// simHashWords splits text into lower case words, dropping punctuation and
+// emoji.
+func simHashWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ParseSimHash parses a fingerprint formatted by SimHash.String.
+func ParseSimHash(s string) (SimHash, error) {
+	v, err := strconv.ParseUint(s, 16, 64)
+	return SimHash(v), err
+}
+
+// String formats the fingerprint as 16 hex digits, the form stored on jobs.
+func (h SimHash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// Distance returns the number of differing bits.
+func (h SimHash) Distance(other SimHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+// Band returns the i-th 8-bit band as 2 hex digits, matching
+// substr(simhash, 2*i+1, 2) of the stored form.
+func (h SimHash) Band(i int) string {
+	return h.String()[2*i : 2*i+2]
+}
@@ -0,0 +1,83 @@
+package core
+
+import "testing"
+
+const simHashPost = `Senior Go Developer в Acme
+Мы ищем опытного бэкенд разработчика в команду платежей.
+Стек: Go, PostgreSQL, Kafka, Kubernetes.
+Зарплата от 300 000 рублей, удаленная работа, гибкий график.
+Пишите @hr_anna`
+
+func TestSimHashDistance(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		maxDist int
+		minDist int
+	}{
+		{"same text", simHashPost, 0, 0},
+		{"case, punctuation and emoji", "🔥 " + simHashPost + "!!!", 0, 0},
+		{"added line", simHashPost + "\n#вакансия", 8, 0},
+		{"different post", "Продаю велосипед, почти новый, в хорошем состоянии, самовывоз из центра Москвы, торг уместен", 64, 9},
+	}
+
+	base := NewSimHash(simHashPost)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := base.Distance(NewSimHash(tt.text))
+			if d > tt.maxDist || d < tt.minDist {
+				t.Errorf("distance = %d, want %d..%d", d, tt.minDist, tt.maxDist)
+			}
+		})
+	}
+}
+
+func TestSimHashEmpty(t *testing.T) {
+	if h := NewSimHash("🔥 !!! 🔥"); h != 0 {
+		t.Errorf("NewSimHash of no words = %v, want 0", h)
+	}
+}
+
+func TestNearMatchable(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{simHashPost, true},
+		{"Go developer, remote", false},
+		{"🔥🔥🔥", false},
+		// 10 words make exactly minShingles shingles
+		{"one two three four five six seven eight nine ten", true},
+		{"one two three four five six seven eight nine", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := NearMatchable(tt.text); got != tt.want {
+				t.Errorf("NearMatchable(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimHashString(t *testing.T) {
+	h := SimHash(0x0123456789abcdef)
+
+	if s := h.String(); s != "0123456789abcdef" {
+		t.Errorf("String() = %q", s)
+	}
+	if b := h.Band(0); b != "01" {
+		t.Errorf("Band(0) = %q, want 01", b)
+	}
+	if b := h.Band(SimHashBands - 1); b != "ef" {
+		t.Errorf("Band(%d) = %q, want ef", SimHashBands-1, b)
+	}
+
+	parsed, err := ParseSimHash(h.String())
+	if err != nil || parsed != h {
+		t.Errorf("ParseSimHash(%q) = %v, %v", h.String(), parsed, err)
+	}
+	if _, err := ParseSimHash("not hex"); err == nil {
+		t.Error("ParseSimHash accepted an invalid fingerprint")
+	}
+}
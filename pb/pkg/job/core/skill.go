@@ -0,0 +1,99 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SkillCategory groups canonical skills for facets.
+type SkillCategory string
+
+const (
+	SkillLanguage  SkillCategory = "language"
+	SkillFramework SkillCategory = "framework"
+	SkillCloud     SkillCategory = "cloud"
+	SkillDB        SkillCategory = "db"
+	SkillTool      SkillCategory = "tool"
+	SkillOther     SkillCategory = "other"
+)
+
+// Skill is a canonical skill with the spellings that resolve to it.
+type Skill struct {
+	ID       string        `json:"id"`
+	Name     string        `json:"name"`
+	Category SkillCategory `json:"category"`
+	Aliases  []string      `json:"aliases"`
+}
+
+// SkillFacet is the number of processed jobs requiring a skill.
+type SkillFacet struct {
+	ID       string `db:"id" json:"id"`
+	Name     string `db:"name" json:"name"`
+	Category string `db:"category" json:"category"`
+	Count    int    `db:"count" json:"count"`
+}
+
+// skillVersion matches trailing versions like "1.21", "v3" or "17+".
+var skillVersion = regexp.MustCompile(`\s+v?\d+(\.\d+)*\+?$`)
+
+// SkillKey normalizes a skill spelling for alias lookup:
+// "Golang 1.21", "GoLang" and " golang " all become "golang".
+func SkillKey(name string) string {
+	key := strings.ToLower(strings.Join(strings.Fields(name), " "))
+	key = skillVersion.ReplaceAllString(key, "")
+	return strings.Trim(key, " ,;:()")
+}
+
+// SkillTaxonomy resolves raw skill names to canonical skills.
+type SkillTaxonomy struct {
+	byKey map[string]Skill
+}
+
+// NewSkillTaxonomy indexes skills by their name and aliases.
+func NewSkillTaxonomy(skills []Skill) *SkillTaxonomy {
+	t := &SkillTaxonomy{byKey: make(map[string]Skill)}
+	for _, skill := range skills {
+		t.byKey[SkillKey(skill.Name)] = skill
+		for _, alias := range skill.Aliases {
+			t.byKey[SkillKey(alias)] = skill
+		}
+	}
+	return t
+}
+
+// Lookup returns the canonical skill for a raw name.
+func (t *SkillTaxonomy) Lookup(name string) (Skill, bool) {
+	if t == nil {
+		return Skill{}, false
+	}
+	skill, ok := t.byKey[SkillKey(name)]
+	return skill, ok
+}
+
+// Resolve maps raw names to canonical names, dropping duplicates.
+// Unknown skills are kept as written. ids holds the canonical skills found.
+func (t *SkillTaxonomy) Resolve(raw []string) (names, ids []string) {
+	seen := make(map[string]bool)
+
+	for _, name := range raw {
+		name = strings.TrimSpace(name)
+		if SkillKey(name) == "" {
+			continue
+		}
+
+		key := "raw:" + SkillKey(name)
+		if skill, ok := t.Lookup(name); ok {
+			name, key = skill.Name, skill.ID
+			if !seen[key] {
+				ids = append(ids, skill.ID)
+			}
+		}
+
+		if !seen[key] {
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+
+	return names, ids
+}
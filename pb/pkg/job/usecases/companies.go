@@ -0,0 +1,408 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"go.uber.org/zap"
+
+	"svpb-tmpl/config"
+	"svpb-tmpl/pkg/job/core"
+)
+
+const companiesCollection = "companies"
+
+// periodFormats maps stats intervals to strftime formats.
+var periodFormats = map[string]string{
+	core.IntervalWeek:  "%Y-W%W",
+	core.IntervalMonth: "%Y-%m",
+}
+
+// Companies implements core.CompanyService on top of the companies collection.
+type Companies struct {
+	app    *pocketbase.PocketBase
+	salary config.SalaryConfig
+	logger *zap.Logger
+}
+
+// NewCompanies creates a new CompanyService implementation.
+func NewCompanies(app *pocketbase.PocketBase, salary config.SalaryConfig, logger *zap.Logger) *Companies {
+	return &Companies{
+		app:    app,
+		salary: salary,
+		logger: logger,
+	}
+}
+
+// Resolve finds or creates the company matching company.Name.
+func (c *Companies) Resolve(ctx context.Context, company core.Company) (string, error) {
+	key := core.CompanyKey(company.Name)
+	if key == "" {
+		return "", nil
+	}
+
+	record, err := findCompany(c.app, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		record, err = c.newCompany(company.Name, key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find company %q: %w", company.Name, err)
+	}
+
+	changed := addToList(record, "contacts", company.Contacts...)
+	changed = addToList(record, "websites", company.Websites...) || changed
+	if !record.IsNew() && !changed {
+		return record.Id, nil
+	}
+
+	if err := c.app.Save(record); err != nil {
+		// Another worker may have created it concurrently
+		if existing, findErr := findCompany(c.app, key); findErr == nil {
+			return existing.Id, nil
+		}
+		return "", fmt.Errorf("failed to save company %q: %w", company.Name, err)
+	}
+
+	if record.IsNew() {
+		c.logger.Debug("Company created", zap.String("name", company.Name), zap.String("key", key))
+	}
+
+	return record.Id, nil
+}
+
+// Get returns a company by id.
+func (c *Companies) Get(ctx context.Context, id string) (core.Company, error) {
+	record, err := c.app.FindRecordById(companiesCollection, id)
+	if err != nil {
+		return core.Company{}, fmt.Errorf("%w: %s", core.ErrCompanyNotFound, id)
+	}
+	return toCompany(record), nil
+}
+
+// Search returns companies whose name or aliases contain query.
+func (c *Companies) Search(ctx context.Context, query string, limit int) ([]core.Company, error) {
+	filter := ""
+	params := dbx.Params{}
+	if query != "" {
+		filter = "name ~ {:query} || key ~ {:key} || aliases ~ {:key}"
+		params["query"] = query
+		params["key"] = core.CompanyKey(query)
+	}
+
+	records, err := c.app.FindRecordsByFilter(companiesCollection, filter, "name", limit, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search companies: %w", err)
+	}
+
+	companies := make([]core.Company, 0, len(records))
+	for _, record := range records {
+		companies = append(companies, toCompany(record))
+	}
+
+	return companies, nil
+}
+
+// Add creates a company or extends the one with the same name.
+func (c *Companies) Add(ctx context.Context, company core.Company) error {
+	key := core.CompanyKey(company.Name)
+	if key == "" {
+		return fmt.Errorf("invalid company name %q", company.Name)
+	}
+
+	record, err := findCompany(c.app, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		record, err = c.newCompany(company.Name, key)
+	}
+	if err != nil {
+		return err
+	}
+
+	addAliasKeys(record, company.Aliases...)
+	addToList(record, "contacts", company.Contacts...)
+	addToList(record, "websites", company.Websites...)
+
+	if err := c.app.Save(record); err != nil {
+		return fmt.Errorf("failed to save company %q: %w", company.Name, err)
+	}
+
+	return nil
+}
+
+// Merge folds sources into target and moves their jobs.
+func (c *Companies) Merge(ctx context.Context, target string, sources []string) (int, error) {
+	moved := 0
+
+	err := c.app.RunInTransaction(func(txApp pbcore.App) error {
+		targetRecord, err := findCompany(txApp, core.CompanyKey(target))
+		if err != nil {
+			return fmt.Errorf("company %q not found: %w", target, err)
+		}
+
+		for _, source := range sources {
+			addAliasKeys(targetRecord, source)
+
+			// Sources may also be spellings without a company of their own
+			sourceRecord, err := findCompany(txApp, core.CompanyKey(source))
+			if err != nil || sourceRecord.Id == targetRecord.Id {
+				continue
+			}
+
+			company := toCompany(sourceRecord)
+			addAliasKeys(targetRecord, sourceRecord.GetString("key"))
+			addAliasKeys(targetRecord, company.Aliases...)
+			addToList(targetRecord, "contacts", company.Contacts...)
+			addToList(targetRecord, "websites", company.Websites...)
+
+			result, err := txApp.DB().Update(
+				"jobs",
+				dbx.Params{"employer": targetRecord.Id},
+				dbx.HashExp{"employer": sourceRecord.Id},
+			).WithContext(ctx).Execute()
+			if err != nil {
+				return fmt.Errorf("failed to move jobs of %q: %w", source, err)
+			}
+			n, _ := result.RowsAffected()
+			moved += int(n)
+
+			if err := txApp.Delete(sourceRecord); err != nil {
+				return fmt.Errorf("failed to delete company %q: %w", source, err)
+			}
+		}
+
+		return txApp.Save(targetRecord)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Jobs with a source spelling but no company yet
+	linked, err := c.Relink(ctx)
+	return moved + linked, err
+}
+
+// Relink links processed jobs that have a company name but no company.
+func (c *Companies) Relink(ctx context.Context) (int, error) {
+	records, err := c.app.FindRecordsByFilter(
+		"jobs",
+		"status = 'processed' && company != '' && employer = ''",
+		"created",
+		0,
+		0,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find jobs: %w", err)
+	}
+
+	linked := 0
+	for _, record := range records {
+		job := core.NewJob(record)
+
+		id, err := c.Resolve(ctx, core.Company{Name: job.ParsedData().Company})
+		if err != nil {
+			return linked, err
+		}
+		if id == "" {
+			continue
+		}
+
+		job.SetCompany(id)
+		if err := c.app.Save(record); err != nil {
+			return linked, fmt.Errorf("failed to save job %s: %w", record.Id, err)
+		}
+		linked++
+	}
+
+	c.logger.Info("Job companies relinked", zap.Int("linked", linked))
+	return linked, nil
+}
+
+// Postings returns the jobs of a company counted by Stats, newest first.
+func (c *Companies) Postings(ctx context.Context, id string) ([]core.CompanyPosting, error) {
+	var postings []core.CompanyPosting
+
+	err := c.app.DB().
+		Select("id", "title", "status", "grade", "location", "isRemote", "salaryMinMonthly", "salaryMaxMonthly", "url", "created").
+		From("jobs").
+		Where(dbx.HashExp{"employer": id, "status": postedStatuses()}).
+		OrderBy("created DESC").
+		WithContext(ctx).
+		All(&postings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list company postings: %w", err)
+	}
+
+	return postings, nil
+}
+
+// Stats aggregates postings and salary ranges of a company per interval.
+// Vacancies closed or withdrawn since count as postings. Only salaries
+// normalized to the current base currency are counted.
+func (c *Companies) Stats(ctx context.Context, id, interval string) (core.CompanyStats, error) {
+	if interval == "" {
+		interval = core.IntervalMonth
+	}
+	format, ok := periodFormats[interval]
+	if !ok {
+		return core.CompanyStats{}, fmt.Errorf("unknown interval %q", interval)
+	}
+
+	company, err := c.Get(ctx, id)
+	if err != nil {
+		return core.CompanyStats{}, err
+	}
+
+	stats := core.CompanyStats{
+		Company:      company,
+		BaseCurrency: c.salary.BaseCurrency,
+		Interval:     interval,
+	}
+
+	where := dbx.HashExp{"employer": id, "status": postedStatuses()}
+
+	var totals struct {
+		Postings  int    `db:"postings"`
+		FirstSeen string `db:"firstSeen"`
+		LastSeen  string `db:"lastSeen"`
+	}
+	err = c.app.DB().
+		Select("COUNT(*) AS postings", "COALESCE(MIN(created), '') AS firstSeen", "COALESCE(MAX(created), '') AS lastSeen").
+		From("jobs").
+		Where(where).
+		WithContext(ctx).
+		One(&totals)
+	if err != nil {
+		return stats, fmt.Errorf("failed to count company postings: %w", err)
+	}
+
+	stats.Postings = totals.Postings
+	stats.FirstSeen.Scan(totals.FirstSeen)
+	stats.LastSeen.Scan(totals.LastSeen)
+
+	if stats.Postings > 0 {
+		months := stats.LastSeen.Time().Sub(stats.FirstSeen.Time()).Hours() / 24 / 30.44
+		stats.PerMonth = float64(stats.Postings) / max(months, 1)
+	}
+
+	// A range with one side missing counts as a point
+	err = c.app.DB().
+		Select(
+			"strftime({:format}, created) AS period",
+			"COUNT(*) AS postings",
+			`COALESCE(MIN(CASE WHEN salaryBaseCurrency = {:base} THEN
+				NULLIF(CASE WHEN salaryMinMonthly > 0 THEN salaryMinMonthly ELSE salaryMaxMonthly END, 0) END), 0) AS salaryMin`,
+			`COALESCE(MAX(CASE WHEN salaryBaseCurrency = {:base} THEN
+				NULLIF(MAX(salaryMinMonthly, salaryMaxMonthly), 0) END), 0) AS salaryMax`,
+		).
+		From("jobs").
+		Where(where).
+		GroupBy("period").
+		OrderBy("period").
+		Bind(dbx.Params{"format": format, "base": c.salary.BaseCurrency}).
+		WithContext(ctx).
+		All(&stats.Periods)
+	if err != nil {
+		return stats, fmt.Errorf("failed to aggregate company postings: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (c *Companies) newCompany(name, key string) (*pbcore.Record, error) {
+	collection, err := c.app.FindCollectionByNameOrId(companiesCollection)
+	if err != nil {
+		return nil, fmt.Errorf("companies collection not found: %w", err)
+	}
+
+	record := pbcore.NewRecord(collection)
+	record.Set("name", strings.TrimSpace(name))
+	record.Set("key", key)
+	return record, nil
+}
+
+// findCompany looks a company up by its key or one of its aliases.
+func findCompany(app pbcore.App, key string) (*pbcore.Record, error) {
+	record := &pbcore.Record{}
+
+	err := app.RecordQuery(companiesCollection).
+		Where(dbx.NewExp(
+			"[[key]] = {:key} OR EXISTS (SELECT 1 FROM json_each([[aliases]]) WHERE value = {:key})",
+			dbx.Params{"key": key},
+		)).
+		OrderBy("[[key]] = {:key} DESC").
+		Limit(1).
+		One(record)
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// addAliasKeys adds the keys of names as aliases, skipping the company's own key.
+func addAliasKeys(record *pbcore.Record, names ...string) {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		if key := core.CompanyKey(name); key != record.GetString("key") {
+			keys = append(keys, key)
+		}
+	}
+	addToList(record, "aliases", keys...)
+}
+
+// addToList appends new non-empty values to a JSON list field.
+// Reports whether anything was added.
+func addToList(record *pbcore.Record, field string, values ...string) bool {
+	var list []string
+	_ = record.UnmarshalJSONField(field, &list)
+
+	added := false
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || containsFold(list, value) {
+			continue
+		}
+		list = append(list, value)
+		added = true
+	}
+
+	if added {
+		record.Set(field, list)
+	}
+	return added
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toCompany(record *pbcore.Record) core.Company {
+	company := core.Company{
+		ID:   record.Id,
+		Name: record.GetString("name"),
+	}
+	_ = record.UnmarshalJSONField("aliases", &company.Aliases)
+	_ = record.UnmarshalJSONField("contacts", &company.Contacts)
+	_ = record.UnmarshalJSONField("websites", &company.Websites)
+
+	return company
+}
+
+// postedStatuses returns core.PostedStatuses for a dbx.HashExp IN condition.
+func postedStatuses() []any {
+	statuses := make([]any, 0, len(core.PostedStatuses))
+	for _, status := range core.PostedStatuses {
+		statuses = append(statuses, string(status))
+	}
+	return statuses
+}
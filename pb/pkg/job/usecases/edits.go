@@ -0,0 +1,192 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"go.uber.org/zap"
+
+	"svpb-tmpl/pkg/job/core"
+)
+
+// Documents returns the documents stored with the job of a message.
+func (s *Service) Documents(ctx context.Context, channelID int64, messageID int) (core.Documents, error) {
+	record, err := s.findMessageRecord(channelID, messageID, 0)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Documents{}, nil
+	}
+	if err != nil {
+		return core.Documents{}, fmt.Errorf("failed to find collected message: %w", err)
+	}
+	return core.NewJob(record).Documents(), nil
+}
+
+// Edit applies an edited message to its job, an edited album to the job of
+// the album. The previous text is stored in jobRevisions. Finished jobs and
+// jobs being extracted are reset to raw, which re-queues them for
+// extraction; a "CLOSED" edit closes the job and its siblings instead.
+func (s *Service) Edit(ctx context.Context, input core.RawJobInput) (core.EditResult, error) {
+	record, err := s.findMessageRecord(input.ChannelID, input.MessageID, input.AlbumID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// A repost keeps the text of the job it was linked to
+		jobID, err := s.findSighting(input.ChannelID, input.MessageID)
+		if err != nil {
+			return core.EditResult{}, fmt.Errorf("failed to find collected message: %w", err)
+		}
+		return core.EditResult{JobID: jobID}, nil
+	}
+	if err != nil {
+		return core.EditResult{}, fmt.Errorf("failed to find collected message: %w", err)
+	}
+
+	job := core.NewJob(record)
+	result := core.EditResult{JobID: job.ID()}
+
+	// Reactions, formatting and link previews edit a message too. Changed
+	// formatting is shown, but doesn't need a re-extraction.
+	before := job.OriginalText()
+	if before == input.OriginalText {
+		if !job.Reformat(input) {
+			return result, nil
+		}
+		if err := s.app.Save(job.Record()); err != nil {
+			return result, fmt.Errorf("failed to save job formatting: %w", err)
+		}
+		return result, nil
+	}
+
+	// The extraction running is of the old text; the worker drops it and
+	// retries, see editedWhileProcessing
+	if job.Status() == core.StatusProcessing {
+		if err := job.ResetToRaw(); err != nil {
+			return result, fmt.Errorf("cannot re-extract job: %w", err)
+		}
+	}
+
+	editedAt := input.EditedAt
+	if editedAt.IsZero() {
+		editedAt = time.Now()
+	}
+
+	// The edited text may duplicate another job; the hash index allows one
+	edited := input
+	taken, err := s.hashTaken(job.ID(), input.Hash)
+	if err != nil {
+		return result, fmt.Errorf("failed to check hash: %w", err)
+	}
+	if taken {
+		edited.Hash = ""
+	}
+
+	previousHash := job.Hash()
+	job.Edit(edited, editedAt)
+
+	switch {
+	case job.Status() == core.StatusClosed:
+		// Closed jobs reopen once the marker they were closed by is removed
+		if core.ClosedByEdit(input.OriginalText, before) {
+			result.Reopened = job.Reopen() == nil
+		}
+	case core.ClosedByEdit(before, input.OriginalText):
+		// Rejected posts stay rejected, whatever the edit says
+		result.Closed = job.Close(editedAt) == nil
+	case job.Status() != core.StatusRaw:
+		if err := job.Reset(); err != nil {
+			return result, fmt.Errorf("cannot re-extract job: %w", err)
+		}
+	}
+
+	err = s.app.RunInTransaction(func(txApp pbcore.App) error {
+		if err := s.saveRevision(txApp, job.ID(), before, previousHash, editedAt); err != nil {
+			return err
+		}
+		if err := txApp.Save(job.Record()); err != nil {
+			return fmt.Errorf("failed to save edited job: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Updated = true
+
+	s.editSiblings(job, edited, editedAt, result.Closed)
+
+	s.logger.Info("Job edited",
+		zap.String("jobId", job.ID()),
+		zap.Int64("channelId", input.ChannelID),
+		zap.Int("messageId", input.MessageID),
+		zap.Bool("closed", result.Closed),
+		zap.Bool("reopened", result.Reopened),
+	)
+
+	return result, nil
+}
+
+// editSiblings carries an edit over to the siblings of a multi-vacancy
+// post. They are re-extracted along with the source job, or closed with it.
+func (s *Service) editSiblings(job *core.Job, input core.RawJobInput, editedAt time.Time, closed bool) {
+	siblings, err := s.findSiblings(job)
+	if err != nil {
+		s.logger.Error("Failed to find sibling jobs", zap.String("jobId", job.ID()), zap.Error(err))
+		return
+	}
+
+	for _, sibling := range siblings {
+		sibling.Edit(input, editedAt)
+		if closed {
+			_ = sibling.Close(editedAt)
+		}
+
+		if err := s.app.Save(sibling.Record()); err != nil {
+			s.logger.Error("Failed to save edited sibling job",
+				zap.String("jobId", sibling.ID()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// saveRevision stores the text a job had before an edit.
+func (s *Service) saveRevision(app pbcore.App, jobID, text, hash string, editedAt time.Time) error {
+	collection, err := app.FindCollectionByNameOrId("jobRevisions")
+	if err != nil {
+		return fmt.Errorf("jobRevisions collection not found: %w", err)
+	}
+
+	revision := pbcore.NewRecord(collection)
+	revision.Set("job", jobID)
+	revision.Set("originalText", text)
+	revision.Set("hash", hash)
+	revision.Set("editedAt", editedAt)
+
+	if err := app.Save(revision); err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+	return nil
+}
+
+// hashTaken returns true if another job was collected with the hash.
+func (s *Service) hashTaken(jobID, hash string) (bool, error) {
+	if hash == "" {
+		return false, nil
+	}
+
+	records, err := s.app.FindRecordsByFilter(
+		"jobs",
+		"subIndex = 0 && hash = {:hash} && id != {:id}",
+		"",
+		1,
+		0,
+		dbx.Params{"hash": hash, "id": jobID},
+	)
+	if err != nil {
+		return false, err
+	}
+	return len(records) > 0, nil
+}
@@ -0,0 +1,62 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"go.uber.org/zap"
+
+	"svpb-tmpl/pkg/job/core"
+)
+
+// Relink rebuilds the links of a chat's jobs and sightings. Posts collected
+// before the chat's username was known link by id, which only opens for
+// members.
+func (s *Service) Relink(ctx context.Context, channelID int64, postURL func(messageID int) (string, bool)) (int, error) {
+	params := dbx.Params{"channelId": fmt.Sprintf("%d", channelID)}
+
+	records, err := s.app.FindRecordsByFilter("jobs", "channelId = {:channelId}", "", 0, 0, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find jobs: %w", err)
+	}
+
+	updated := 0
+	for _, record := range records {
+		url, _ := postURL(record.GetInt("messageId"))
+
+		job := core.NewJob(record)
+		if !job.SetURL(url) {
+			continue
+		}
+		if err := s.app.Save(job.Record()); err != nil {
+			return updated, fmt.Errorf("failed to save job link: %w", err)
+		}
+		updated++
+	}
+
+	sightings, err := s.app.FindRecordsByFilter("job_sightings", "channelId = {:channelId}", "", 0, 0, params)
+	if err != nil {
+		return updated, fmt.Errorf("failed to find sightings: %w", err)
+	}
+
+	for _, sighting := range sightings {
+		url, _ := postURL(sighting.GetInt("messageId"))
+		if sighting.GetString("url") == url {
+			continue
+		}
+		sighting.Set("url", url)
+		if err := s.app.Save(sighting); err != nil {
+			return updated, fmt.Errorf("failed to save sighting link: %w", err)
+		}
+	}
+
+	if updated > 0 {
+		s.logger.Info("Jobs relinked",
+			zap.Int64("channelId", channelID),
+			zap.Int("updated", updated),
+		)
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,286 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"go.uber.org/zap"
+
+	"svpb-tmpl/config"
+	"svpb-tmpl/pkg/job/core"
+)
+
+const queueCollection = "jobQueue"
+
+// Queue implements core.JobQueue on top of the jobQueue collection.
+type Queue struct {
+	app    *pocketbase.PocketBase
+	cfg    config.QueueConfig
+	logger *zap.Logger
+}
+
+// NewQueue creates a new JobQueue implementation.
+func NewQueue(app *pocketbase.PocketBase, cfg config.QueueConfig, logger *zap.Logger) *Queue {
+	return &Queue{
+		app:    app,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Enqueue schedules a job for processing.
+func (q *Queue) Enqueue(ctx context.Context, jobID string) error {
+	return q.enqueue(q.app, jobID)
+}
+
+func (q *Queue) enqueue(app pbcore.App, jobID string) error {
+	existing, err := app.FindFirstRecordByFilter(queueCollection, "job = {:jobId}", map[string]any{
+		"jobId": jobID,
+	})
+	if err == nil {
+		entry := core.NewQueueEntry(existing)
+		if entry.Status() != core.QueueDead {
+			return nil
+		}
+		entry.Revive()
+		return app.Save(entry.Record())
+	}
+
+	collection, err := app.FindCollectionByNameOrId(queueCollection)
+	if err != nil {
+		return fmt.Errorf("jobQueue collection not found: %w", err)
+	}
+
+	// Jobs of priority sources are extracted first
+	priority := 0
+	if job, err := app.FindRecordById("jobs", jobID); err == nil {
+		priority = job.GetInt("priority")
+	}
+
+	entry := core.NewPendingQueueEntry(collection, jobID, priority)
+	if err := app.Save(entry.Record()); err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	return nil
+}
+
+// Lease claims the next due entry, including entries whose lease has expired.
+func (q *Queue) Lease(ctx context.Context) (*core.QueueItem, error) {
+	var item *core.QueueItem
+
+	err := q.app.RunInTransaction(func(txApp pbcore.App) error {
+		now := types.NowDateTime()
+
+		records, err := txApp.FindRecordsByFilter(
+			queueCollection,
+			"(status = {:pending} && runAt <= {:now}) || (status = {:leased} && leasedUntil <= {:now})",
+			"-priority,runAt",
+			1,
+			0,
+			map[string]any{
+				"pending": string(core.QueuePending),
+				"leased":  string(core.QueueLeased),
+				"now":     now.String(),
+			},
+		)
+		if err != nil || len(records) == 0 {
+			return err
+		}
+
+		entry := core.NewQueueEntry(records[0])
+
+		// The previous holder's lease expired: its job may be stuck in
+		// processing, which the new holder couldn't start from
+		if entry.Status() == core.QueueLeased {
+			if err := q.resetStuck(txApp, entry.JobID()); err != nil {
+				return err
+			}
+		}
+
+		if err := entry.Lease(now.Time().Add(q.cfg.LeaseTimeout)); err != nil {
+			return err
+		}
+		if err := txApp.Save(entry.Record()); err != nil {
+			return err
+		}
+
+		leased := entry.Item()
+		item = &leased
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease queue entry: %w", err)
+	}
+
+	return item, nil
+}
+
+// resetStuck moves a job left in processing back to raw.
+func (q *Queue) resetStuck(app pbcore.App, jobID string) error {
+	record, err := app.FindRecordById("jobs", jobID)
+	if err != nil {
+		return nil // Processing fails with job not found
+	}
+
+	job := core.NewJob(record)
+	if job.Status() != core.StatusProcessing {
+		return nil
+	}
+	if err := job.ResetToRaw(); err != nil {
+		return err
+	}
+
+	q.logger.Warn("Lease expired, job taken over", zap.String("jobId", jobID))
+	return app.Save(job.Record())
+}
+
+// Ack removes a successfully processed entry.
+func (q *Queue) Ack(ctx context.Context, item core.QueueItem) error {
+	record, err := q.app.FindRecordById(queueCollection, item.ID)
+	if err != nil {
+		return nil // Already gone, e.g. the job was deleted
+	}
+
+	return q.app.Delete(record)
+}
+
+// Nack schedules a retry with exponential backoff or buries the entry.
+func (q *Queue) Nack(ctx context.Context, item core.QueueItem, cause error) error {
+	record, err := q.app.FindRecordById(queueCollection, item.ID)
+	if err != nil {
+		return nil
+	}
+
+	entry := core.NewQueueEntry(record)
+	reason := cause.Error()
+
+	if entry.Attempts() >= q.cfg.MaxAttempts {
+		q.logger.Warn("Job exhausted queue attempts",
+			zap.String("jobId", item.JobID),
+			zap.Int("attempts", entry.Attempts()),
+			zap.String("lastError", reason),
+		)
+		if err := entry.Bury(reason); err != nil {
+			return err
+		}
+		return q.app.Save(entry.Record())
+	}
+
+	delay := q.backoff(entry.Attempts())
+	if err := entry.Retry(time.Now().Add(delay), reason); err != nil {
+		return err
+	}
+
+	q.logger.Info("Job scheduled for retry",
+		zap.String("jobId", item.JobID),
+		zap.Int("attempt", entry.Attempts()),
+		zap.Duration("delay", delay),
+	)
+
+	return q.app.Save(entry.Record())
+}
+
+// Sweep re-queues jobs left in raw or processing state, e.g. after a crash.
+// Leased entries keep their attempts; jobs whose entry exhausted them stay
+// dead, and those left in processing are failed so they can be retried by hand.
+// Must only run while no worker holds a lease.
+func (q *Queue) Sweep(ctx context.Context) (int, error) {
+	records, err := q.app.FindRecordsByFilter(
+		"jobs",
+		"status = {:raw} || status = {:processing}",
+		"created",
+		0,
+		0,
+		map[string]any{
+			"raw":        string(core.StatusRaw),
+			"processing": string(core.StatusProcessing),
+		},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find unfinished jobs: %w", err)
+	}
+
+	count := 0
+	for _, record := range records {
+		job := core.NewJob(record)
+
+		requeued := false
+		err := q.app.RunInTransaction(func(txApp pbcore.App) error {
+			existing, findErr := txApp.FindFirstRecordByFilter(queueCollection, "job = {:jobId}", map[string]any{
+				"jobId": job.ID(),
+			})
+
+			// Every lease counts as an attempt, so a job that keeps taking the
+			// process down ends up dead like one that keeps failing
+			if findErr == nil {
+				entry := core.NewQueueEntry(existing)
+				if entry.Status() == core.QueueLeased && entry.Attempts() >= q.cfg.MaxAttempts {
+					if err := entry.Bury("process stopped while the job was leased"); err != nil {
+						return err
+					}
+					if err := txApp.Save(entry.Record()); err != nil {
+						return err
+					}
+				}
+			}
+
+			if findErr == nil && core.NewQueueEntry(existing).Status() == core.QueueDead {
+				if job.Status() != core.StatusProcessing {
+					return nil
+				}
+				if err := job.Fail("queue attempts exhausted", errors.New(existing.GetString("lastError"))); err != nil {
+					return err
+				}
+				return txApp.Save(job.Record())
+			}
+
+			if job.Status() == core.StatusProcessing {
+				if err := job.ResetToRaw(); err != nil {
+					return err
+				}
+				if err := txApp.Save(job.Record()); err != nil {
+					return err
+				}
+			}
+			requeued = true
+
+			// Release leases held by a previous process
+			if findErr == nil {
+				entry := core.NewQueueEntry(existing)
+				if entry.Status() == core.QueueLeased {
+					entry.Release()
+					return txApp.Save(entry.Record())
+				}
+			}
+
+			return q.enqueue(txApp, job.ID())
+		})
+		if err != nil {
+			q.logger.Error("Failed to re-queue job", zap.Error(err), zap.String("jobId", job.ID()))
+			continue
+		}
+		if requeued {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// backoff returns the retry delay after the given number of attempts.
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= q.cfg.MaxBackoff {
+			return q.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,111 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pocketbase/pocketbase"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"go.uber.org/zap"
+
+	"svpb-tmpl/pkg/job/core"
+)
+
+const ratesCollection = "exchangeRates"
+
+// Rates implements core.ExchangeRates on top of the exchangeRates collection.
+type Rates struct {
+	app    *pocketbase.PocketBase
+	logger *zap.Logger
+}
+
+// NewRates creates a new ExchangeRates implementation.
+func NewRates(app *pocketbase.PocketBase, logger *zap.Logger) *Rates {
+	return &Rates{
+		app:    app,
+		logger: logger,
+	}
+}
+
+// Convert converts an amount between currencies through USD.
+func (r *Rates) Convert(ctx context.Context, amount float64, from, to string) (float64, error) {
+	from, to = core.NormalizeCurrency(from), core.NormalizeCurrency(to)
+	if from == to {
+		return amount, nil
+	}
+
+	fromRate, err := r.perUSD(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := r.perUSD(to)
+	if err != nil {
+		return 0, err
+	}
+
+	return amount / fromRate * toRate, nil
+}
+
+// List returns all known rates.
+func (r *Rates) List(ctx context.Context) ([]core.ExchangeRate, error) {
+	records, err := r.app.FindRecordsByFilter(ratesCollection, "", "currency", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+
+	rates := make([]core.ExchangeRate, 0, len(records))
+	for _, record := range records {
+		rates = append(rates, core.ExchangeRate{
+			Currency: record.GetString("currency"),
+			PerUSD:   record.GetFloat("perUsd"),
+			Updated:  record.GetDateTime("updated").Time(),
+		})
+	}
+
+	return rates, nil
+}
+
+// Set creates or updates the rate of a currency.
+func (r *Rates) Set(ctx context.Context, currency string, perUSD float64) error {
+	if perUSD <= 0 {
+		return fmt.Errorf("rate must be positive, got %v", perUSD)
+	}
+
+	currency = core.NormalizeCurrency(currency)
+
+	record, err := r.app.FindFirstRecordByData(ratesCollection, "currency", currency)
+	if err != nil {
+		collection, err := r.app.FindCollectionByNameOrId(ratesCollection)
+		if err != nil {
+			return fmt.Errorf("exchangeRates collection not found: %w", err)
+		}
+		record = pbcore.NewRecord(collection)
+		record.Set("currency", currency)
+	}
+
+	record.Set("perUsd", perUSD)
+
+	if err := r.app.Save(record); err != nil {
+		return fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+
+	r.logger.Info("Exchange rate updated",
+		zap.String("currency", currency),
+		zap.Float64("perUsd", perUSD),
+	)
+
+	return nil
+}
+
+// perUSD returns the price of one US dollar in currency.
+func (r *Rates) perUSD(currency string) (float64, error) {
+	record, err := r.app.FindFirstRecordByData(ratesCollection, "currency", currency)
+	if err != nil {
+		if currency == "USD" {
+			return 1, nil
+		}
+		return 0, fmt.Errorf("%w: %q", core.ErrUnknownCurrency, currency)
+	}
+
+	return record.GetFloat("perUsd"), nil
+}
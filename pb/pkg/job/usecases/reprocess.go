@@ -0,0 +1,212 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+	"go.uber.org/zap"
+
+	"svpb-tmpl/pkg/job/core"
+	usagecore "svpb-tmpl/pkg/usage/core"
+)
+
+// FindForReprocess returns ids of jobs matching the filter, oldest first.
+// Siblings of multi-vacancy posts are refreshed with their source job.
+func (s *Service) FindForReprocess(ctx context.Context, filter core.ReprocessFilter) ([]string, error) {
+	parts := []string{"subIndex = 0"}
+	params := map[string]any{}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, 0, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			key := fmt.Sprintf("status%d", i)
+			statuses = append(statuses, "status = {:"+key+"}")
+			params[key] = string(status)
+		}
+		parts = append(parts, "("+strings.Join(statuses, " || ")+")")
+	}
+
+	if !filter.From.IsZero() {
+		parts = append(parts, "created >= {:from}")
+		params["from"] = filter.From.UTC().Format(types.DefaultDateLayout)
+	}
+
+	if !filter.To.IsZero() {
+		parts = append(parts, "created < {:to}")
+		params["to"] = filter.To.UTC().Format(types.DefaultDateLayout)
+	}
+
+	if filter.ChannelID != "" {
+		parts = append(parts, "channelId = {:channelId}")
+		params["channelId"] = filter.ChannelID
+	}
+
+	if filter.Expr != "" {
+		parts = append(parts, "("+filter.Expr+")")
+	}
+
+	records, err := s.app.FindRecordsByFilter(
+		"jobs",
+		strings.Join(parts, " && "),
+		"created",
+		filter.Limit,
+		0,
+		params,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find jobs: %w", err)
+	}
+
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.Id)
+	}
+
+	return ids, nil
+}
+
+// Reprocess resets the given jobs to raw and re-extracts them with bounded
+// concurrency. The run stops with usagecore.ErrBudgetExceeded once the daily
+// extraction budget is spent.
+func (s *Service) Reprocess(
+	ctx context.Context,
+	jobIDs []string,
+	opts core.ReprocessOptions,
+	progress func(core.ReprocessResult),
+) (core.ReprocessReport, error) {
+	report := core.ReprocessReport{
+		Matched: len(jobIDs),
+		DryRun:  opts.DryRun,
+	}
+
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+
+	concurrency := max(opts.Concurrency, 1)
+	ids := make(chan string)
+	results := make(chan core.ReprocessResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				if err := s.checkBudget(ctx); err != nil {
+					stop(err)
+					return
+				}
+				results <- s.reprocessOne(ctx, id, opts.DryRun)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(ids)
+		for _, id := range jobIDs {
+			select {
+			case ids <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		if result.Error != "" {
+			report.Errors++
+		} else {
+			report.Done++
+		}
+		report.Results = append(report.Results, result)
+
+		if progress != nil {
+			progress(result)
+		}
+	}
+
+	s.logger.Info("Reprocess finished",
+		zap.Int("matched", report.Matched),
+		zap.Int("done", report.Done),
+		zap.Int("errors", report.Errors),
+		zap.Bool("dryRun", report.DryRun),
+	)
+
+	return report, context.Cause(ctx)
+}
+
+// checkBudget returns usagecore.ErrBudgetExceeded if extraction has spent
+// its daily budget.
+func (s *Service) checkBudget(ctx context.Context) error {
+	if s.usage == nil {
+		return nil
+	}
+	return s.usage.CheckBudget(ctx, usagecore.StageExtraction)
+}
+
+// reprocessOne re-extracts a single job. In dry-run mode nothing is saved
+// and Status is the status the job would end up in.
+func (s *Service) reprocessOne(ctx context.Context, jobID string, dryRun bool) core.ReprocessResult {
+	result := core.ReprocessResult{JobID: jobID}
+
+	// Reprocessing asks for a fresh extraction, never a cached one. Dry runs
+	// leave the cache as it is.
+	if dryRun {
+		ctx = core.BypassCache(ctx)
+	} else {
+		ctx = core.SkipCache(ctx)
+	}
+
+	record, err := s.app.FindRecordById("jobs", jobID)
+	if err != nil {
+		result.Error = fmt.Sprintf("job not found: %v", err)
+		return result
+	}
+
+	job := core.NewJob(record)
+	before := job.ParsedData()
+	result.Status = job.Status()
+
+	if err := job.Reset(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if dryRun {
+		extraction, err := s.extractor.Extract(usagecore.WithJob(ctx, jobID), job.ExtractionText())
+		if err != nil {
+			result.Error = fmt.Sprintf("extraction failed: %v", err)
+			return result
+		}
+
+		after, found := extraction.Vacancy(job.SubIndex())
+
+		result.Status = core.StatusRejected
+		if found && after.IsVacancy {
+			result.Status = core.StatusProcessed
+		}
+		// Compare skills and contacts as a real run would store them
+		after.Skills, _ = s.taxonomy(ctx).Resolve(after.Skills)
+		after.Contacts = core.WithoutChannel(core.MergeContacts(job.MessageContacts(), after.Contacts), job.ChannelUsername())
+
+		result.Changes = core.DiffParsedData(before, after)
+		return result
+	}
+
+	err = s.process(ctx, job)
+	result.Status = job.Status()
+	result.Changes = core.DiffParsedData(before, job.ParsedData())
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
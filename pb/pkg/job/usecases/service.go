@@ -3,49 +3,124 @@ package usecases
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 
 	"github.com/pocketbase/pocketbase"
 	pbcore "github.com/pocketbase/pocketbase/core"
 	"go.uber.org/zap"
 
+	"svpb-tmpl/config"
 	"svpb-tmpl/pkg/job/core"
+	usagecore "svpb-tmpl/pkg/usage/core"
 )
 
+// errEditedWhileProcessing fails an extraction of a job whose text was
+// edited meanwhile, so the queue retries it with the new text.
+var errEditedWhileProcessing = errors.New("job edited while processing")
+
 // Service implements core.JobService.
 type Service struct {
 	app       *pocketbase.PocketBase
 	extractor core.JobExtractor
+	prefill   core.JobExtractor
 	offerGen  core.OfferGenerator
+	skills    core.SkillService
+	companies core.CompanyService
+	rates     core.ExchangeRates
+	salary    config.SalaryConfig
+	dedup     config.DedupConfig
+	usage     usagecore.UsageService
 	logger    *zap.Logger
 }
 
-// NewService creates a new JobService implementation.
+// NewService creates a new JobService implementation. prefill is a free
+// extractor, e.g. the rules, that decides whether a near-duplicate is linked
+// without an extraction; nil extracts every near-duplicate.
 func NewService(
 	app *pocketbase.PocketBase,
 	extractor core.JobExtractor,
+	prefill core.JobExtractor,
 	offerGen core.OfferGenerator,
+	skills core.SkillService,
+	companies core.CompanyService,
+	rates core.ExchangeRates,
+	salary config.SalaryConfig,
+	dedup config.DedupConfig,
+	usage usagecore.UsageService,
 	logger *zap.Logger,
 ) *Service {
 	return &Service{
 		app:       app,
 		extractor: extractor,
+		prefill:   prefill,
 		offerGen:  offerGen,
+		skills:    skills,
+		companies: companies,
+		rates:     rates,
+		salary:    salary,
+		dedup:     dedup,
+		usage:     usage,
 		logger:    logger,
 	}
 }
 
-// SubmitRaw creates a new job in raw state.
-func (s *Service) SubmitRaw(ctx context.Context, input core.RawJobInput) (string, error) {
+// SubmitRaw creates a new job in raw state. A repost of a text collected
+// before becomes a sighting of the existing job, and so does a
+// near-duplicate the pre-fill reads as the same vacancy. Other
+// near-duplicates are extracted like a new post and only folded into the
+// job they resemble if they turn out the same vacancy, see foldRepost.
+// Unique indexes on the message and the hash make it safe against
+// concurrent deliveries of the same message: the loser gets the winner's job.
+func (s *Service) SubmitRaw(ctx context.Context, input core.RawJobInput) (core.SubmitResult, error) {
+	jobID, err := s.findMessageJob(input.ChannelID, input.MessageID, input.AlbumID)
+	if err != nil {
+		return core.SubmitResult{}, fmt.Errorf("failed to find collected message: %w", err)
+	}
+	if jobID != "" {
+		return core.SubmitResult{JobID: jobID}, nil
+	}
+
+	canonical, err := s.findCanonical(input)
+	if err != nil {
+		return core.SubmitResult{}, fmt.Errorf("failed to find reposted job: %w", err)
+	}
+	if canonical != nil {
+		return s.submitRepost(canonical, input, 0)
+	}
+
 	collection, err := s.app.FindCollectionByNameOrId("jobs")
 	if err != nil {
-		return "", fmt.Errorf("jobs collection not found: %w", err)
+		return core.SubmitResult{}, fmt.Errorf("jobs collection not found: %w", err)
 	}
 
 	job := core.NewRawJob(collection, input)
 
+	near, distance, err := s.findNearDuplicate(input)
+	if err != nil {
+		s.logger.Warn("Near-duplicates not looked up", zap.Error(err))
+	} else if near != nil {
+		if s.samePrefill(ctx, near, input) {
+			return s.submitRepost(near, input, distance)
+		}
+		job.SetRepostOf(near.ID(), distance)
+	}
+
 	if err := s.app.Save(job.Record()); err != nil {
-		return "", fmt.Errorf("failed to save raw job: %w", err)
+		// A concurrent delivery of the message or its text may have won
+		if jobID, _ := s.findMessageJob(input.ChannelID, input.MessageID, input.AlbumID); jobID != "" {
+			return core.SubmitResult{JobID: jobID}, nil
+		}
+		if canonical, _ := s.findCanonical(input); canonical != nil {
+			return s.submitRepost(canonical, input, 0)
+		}
+		return core.SubmitResult{}, fmt.Errorf("failed to save raw job: %w", err)
+	}
+
+	// Counters of a new job already account for its own message
+	if _, err := s.saveSighting(job.ID(), input, 0); err != nil {
+		s.logger.Warn("Sighting not recorded", zap.String("jobId", job.ID()), zap.Error(err))
 	}
 
 	s.logger.Info("Raw job submitted",
@@ -54,10 +129,30 @@ func (s *Service) SubmitRaw(ctx context.Context, input core.RawJobInput) (string
 		zap.Int("messageId", input.MessageID),
 	)
 
-	return job.ID(), nil
+	return core.SubmitResult{JobID: job.ID(), Created: true}, nil
+}
+
+// submitRepost records the message as a sighting of canonical.
+func (s *Service) submitRepost(canonical *core.Job, input core.RawJobInput, distance int) (core.SubmitResult, error) {
+	recorded, err := s.recordSighting(canonical, input, distance)
+	if err != nil {
+		return core.SubmitResult{}, err
+	}
+
+	if recorded {
+		s.logger.Info("Repost recorded",
+			zap.String("jobId", canonical.ID()),
+			zap.Int64("channelId", input.ChannelID),
+			zap.Int("messageId", input.MessageID),
+			zap.Int("distance", distance),
+		)
+	}
+
+	return core.SubmitResult{JobID: canonical.ID(), Repost: recorded}, nil
 }
 
 // Process runs LLM extraction on a raw job.
+// Failed jobs are moved back to raw first, so queue retries pick them up.
 func (s *Service) Process(ctx context.Context, jobID string) error {
 	record, err := s.app.FindRecordById("jobs", jobID)
 	if err != nil {
@@ -66,8 +161,21 @@ func (s *Service) Process(ctx context.Context, jobID string) error {
 
 	job := core.NewJob(record)
 
+	if job.Status() == core.StatusFailed {
+		if err := job.Retry(); err != nil {
+			return fmt.Errorf("cannot retry job: %w", err)
+		}
+	}
+
+	return s.process(ctx, job)
+}
+
+// process runs the extraction pipeline on a raw job and persists the outcome.
+func (s *Service) process(ctx context.Context, job *core.Job) error {
+	jobID := job.ID()
+
 	if err := job.MarkProcessing(); err != nil {
-		return fmt.Errorf("cannot process job: %w", err)
+		return fmt.Errorf("%w: %s is %s", core.ErrNotProcessable, jobID, job.Status())
 	}
 
 	// Save processing state
@@ -76,18 +184,49 @@ func (s *Service) Process(ctx context.Context, jobID string) error {
 	}
 
 	// Extract data using LLM
-	parsed, err := s.extractor.Extract(ctx, job.OriginalText())
+	extraction, err := s.extractor.Extract(usagecore.WithJob(ctx, jobID), job.ExtractionText())
+	if edited, editErr := s.editedWhileProcessing(job); edited {
+		return editErr
+	}
 	if err != nil {
 		s.logger.Error("LLM extraction failed",
 			zap.Error(err),
 			zap.String("jobId", jobID),
 		)
-		if rejectErr := job.Reject("LLM extraction failed"); rejectErr == nil {
+		if failErr := job.Fail("LLM extraction failed", err); failErr == nil {
 			s.app.Save(job.Record())
 		}
 		return fmt.Errorf("extraction failed: %w", err)
 	}
 
+	// Siblings of a multi-vacancy post take their own vacancy of the post
+	parsed, found := extraction.Vacancy(job.SubIndex())
+	job.SetProvenance(extraction.Provenance)
+	job.SetWarnings(extraction.Warnings)
+
+	// Reposts may have been recorded while extracting
+	if job.SubIndex() == 0 {
+		s.refreshSightings(job)
+	}
+
+	if len(extraction.Warnings) > 0 {
+		s.logger.Warn("Extraction failed sanity checks",
+			zap.String("jobId", jobID),
+			zap.Strings("warnings", extraction.Warnings),
+		)
+	}
+
+	if !found {
+		s.logger.Info("Sibling vacancy no longer in the post",
+			zap.String("jobId", jobID),
+			zap.Int("subIndex", job.SubIndex()),
+		)
+		if err := job.Reject(reasonVacancyGone); err == nil {
+			s.app.Save(job.Record())
+		}
+		return nil
+	}
+
 	// Check if it's actually a vacancy
 	if !parsed.IsVacancy {
 		s.logger.Info("LLM determined not a vacancy",
@@ -96,14 +235,22 @@ func (s *Service) Process(ctx context.Context, jobID string) error {
 		if err := job.Reject("not a vacancy"); err == nil {
 			s.app.Save(job.Record())
 		}
+		s.syncSiblings(ctx, job, extraction)
 		return nil
 	}
 
 	// Complete with parsed data
-	if err := job.Complete(parsed); err != nil {
+	if err := job.Complete(parsed, s.taxonomy(ctx)); err != nil {
 		return fmt.Errorf("failed to complete job: %w", err)
 	}
 
+	if s.foldRepost(job, parsed, extraction) {
+		return nil
+	}
+
+	s.normalizeSalary(ctx, job)
+	s.linkCompany(ctx, job)
+
 	if err := s.app.Save(job.Record()); err != nil {
 		return fmt.Errorf("failed to save processed job: %w", err)
 	}
@@ -113,16 +260,196 @@ func (s *Service) Process(ctx context.Context, jobID string) error {
 		zap.String("title", parsed.Title),
 	)
 
+	s.syncSiblings(ctx, job, extraction)
+
+	// Posts deleted while extracting
+	s.withdrawDeleted(job)
+
+	return nil
+}
+
+// editedWhileProcessing reports whether the job was edited while it was
+// extracted. The edit wins: the extraction of the old text is dropped and a
+// job reset to raw is retried with the new one, returning an error so the
+// queue schedules it again. Closed or deleted jobs are done.
+func (s *Service) editedWhileProcessing(job *core.Job) (bool, error) {
+	record, err := s.app.FindRecordById("jobs", job.ID())
+	if err != nil {
+		return true, nil
+	}
+
+	current := core.NewJob(record)
+	if current.Status() == core.StatusProcessing && current.OriginalText() == job.OriginalText() {
+		return false, nil
+	}
+
+	s.logger.Info("Job edited while processing, extraction dropped",
+		zap.String("jobId", job.ID()),
+		zap.String("status", string(current.Status())),
+	)
+	if current.Status() == core.StatusRaw {
+		return true, errEditedWhileProcessing
+	}
+	return true, nil
+}
+
+// taxonomy returns the skills taxonomy. Without it skills are stored as
+// extracted and can be resolved later with a relink.
+func (s *Service) taxonomy(ctx context.Context) *core.SkillTaxonomy {
+	taxonomy, err := s.skills.Taxonomy(ctx)
+	if err != nil {
+		s.logger.Warn("Skills taxonomy unavailable", zap.Error(err))
+		return nil
+	}
+	return taxonomy
+}
+
+// linkCompany links the job to its deduplicated company and remembers the
+// recruiters' Telegram contacts on it. Jobs left unlinked are picked up by a
+// companies relink.
+func (s *Service) linkCompany(ctx context.Context, job *core.Job) {
+	data := job.ParsedData()
+
+	company := core.Company{Name: data.Company}
+	for _, contact := range data.Contacts {
+		if contact.Kind == core.ContactTelegram {
+			company.Contacts = append(company.Contacts, "@"+contact.Value)
+		}
+	}
+
+	companyID, err := s.companies.Resolve(ctx, company)
+	if err != nil {
+		s.logger.Warn("Company not resolved",
+			zap.String("jobId", job.ID()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	job.SetCompany(companyID)
+}
+
+// normalizeSalary converts the job's salary to a monthly amount in the base
+// currency and tax basis so jobs can be compared regardless of period,
+// currency and taxes. Salaries posted without a period that are too large
+// for a month are taken as yearly.
+func (s *Service) normalizeSalary(ctx context.Context, job *core.Job) {
+	data := job.ParsedData()
+	base := s.salary.BaseCurrency
+
+	if data.SalaryMin == 0 && data.SalaryMax == 0 {
+		job.SetMonthlySalary(0, 0, "")
+		return
+	}
+
+	period := data.SalaryPeriod
+	if period == "" {
+		if usd, err := s.rates.Convert(ctx, float64(max(data.SalaryMin, data.SalaryMax)), data.Currency, "USD"); err == nil {
+			period = core.InferSalaryPeriod(usd)
+			job.SetSalaryPeriod(period)
+		}
+	}
+
+	convert := func(amount int) (int, error) {
+		if amount == 0 {
+			return 0, nil
+		}
+		monthly := core.MonthlySalary(amount, period)
+		monthly = core.SalaryWithTax(monthly, data.SalaryTax, core.SalaryTax(s.salary.TaxBasis), s.salary.TaxRate)
+		v, err := s.rates.Convert(ctx, monthly, data.Currency, base)
+		return int(math.Round(v)), err
+	}
+
+	minMonthly, err := convert(data.SalaryMin)
+	var maxMonthly int
+	if err == nil {
+		maxMonthly, err = convert(data.SalaryMax)
+	}
+	if err != nil {
+		s.logger.Debug("Salary not normalized",
+			zap.String("jobId", job.ID()),
+			zap.Error(err),
+		)
+		job.SetMonthlySalary(0, 0, "")
+		return
+	}
+
+	job.SetMonthlySalary(minMonthly, maxMonthly, base)
+}
+
+// NormalizeSalaries recomputes monthly base currency salaries of processed jobs.
+func (s *Service) NormalizeSalaries(ctx context.Context, currency string) (int, error) {
+	filter := "status = 'processed' && (salaryMin > 0 || salaryMax > 0)"
+	params := map[string]any{}
+
+	if currency != "" {
+		filter += " && currency = {:currency}"
+		params["currency"] = core.NormalizeCurrency(currency)
+	}
+
+	records, err := s.app.FindRecordsByFilter("jobs", filter, "", 0, 0, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find jobs: %w", err)
+	}
+
+	updated := 0
+	for _, record := range records {
+		job := core.NewJob(record)
+		s.normalizeSalary(ctx, job)
+
+		if err := s.app.Save(job.Record()); err != nil {
+			return updated, fmt.Errorf("failed to save job %s: %w", job.ID(), err)
+		}
+		updated++
+	}
+
+	s.logger.Info("Salaries normalized",
+		zap.String("currency", currency),
+		zap.Int("updated", updated),
+	)
+
+	return updated, nil
+}
+
+// Retry moves a failed job back to raw so it gets queued again.
+func (s *Service) Retry(ctx context.Context, jobID string) error {
+	record, err := s.app.FindRecordById("jobs", jobID)
+	if err != nil {
+		return fmt.Errorf("job not found: %w", err)
+	}
+
+	job := core.NewJob(record)
+
+	if err := job.Retry(); err != nil {
+		return fmt.Errorf("cannot retry job: %w", err)
+	}
+
+	if err := s.app.Save(job.Record()); err != nil {
+		return fmt.Errorf("failed to save retried job: %w", err)
+	}
+
+	s.logger.Info("Job scheduled for retry",
+		zap.String("jobId", jobID),
+		zap.Int("attempts", job.Attempts()),
+	)
+
 	return nil
 }
 
 // GenerateOffer creates a personalized offer message for a job.
 func (s *Service) GenerateOffer(ctx context.Context, jobID, userID string) (string, error) {
+	if err := s.usage.CheckBudget(ctx, usagecore.StageOffer); err != nil {
+		return "", err
+	}
+
 	// Get job
 	job, err := s.app.FindRecordById("jobs", jobID)
 	if err != nil {
 		return "", fmt.Errorf("job not found: %w", err)
 	}
+	if !core.NewJob(job).IsAvailable() {
+		return "", core.ErrJobUnavailable
+	}
 
 	// Get user with CV
 	user, err := s.app.FindRecordById("users", userID)
@@ -136,6 +463,7 @@ func (s *Service) GenerateOffer(ctx context.Context, jobID, userID string) (stri
 	jobDescription := job.GetString("description") + "\n" + job.GetString("originalText")
 
 	// Generate offer
+	ctx = usagecore.WithJob(usagecore.WithUser(ctx, userID), jobID)
 	offer, err := s.offerGen.Generate(ctx, string(cvBytes), jobDescription)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate offer: %w", err)
@@ -149,32 +477,6 @@ func (s *Service) GenerateOffer(ctx context.Context, jobID, userID string) (stri
 	return offer, nil
 }
 
-// CheckDuplicate returns true if a job with same channelID/messageID or hash exists.
-func (s *Service) CheckDuplicate(ctx context.Context, channelID int64, messageID int, hash string) (bool, error) {
-	collection, err := s.app.FindCollectionByNameOrId("jobs")
-	if err != nil {
-		return false, err
-	}
-
-	records, err := s.app.FindRecordsByFilter(
-		collection.Id,
-		"(channelId = {:channelId} && messageId = {:messageId}) || hash = {:hash}",
-		"",
-		1,
-		0,
-		map[string]any{
-			"channelId": fmt.Sprintf("%d", channelID),
-			"messageId": messageID,
-			"hash":      hash,
-		},
-	)
-	if err != nil {
-		return false, err
-	}
-
-	return len(records) > 0, nil
-}
-
 // saveUserJobOffer saves the offer to userJobMap collection.
 func (s *Service) saveUserJobOffer(userID, jobID, offer string) error {
 	collection, err := s.app.FindCollectionByNameOrId("userJobMap")
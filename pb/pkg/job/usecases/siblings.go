@@ -0,0 +1,144 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/pocketbase/dbx"
+	"go.uber.org/zap"
+
+	"svpb-tmpl/pkg/job/core"
+)
+
+// reasonVacancyGone rejects siblings whose vacancy disappeared from the post
+// on re-extraction.
+const reasonVacancyGone = "vacancy no longer found in the post"
+
+// syncSiblings completes a sibling job for every further vacancy of a
+// multi-vacancy post and rejects siblings left over from an earlier
+// extraction. Siblings are created already processed, so they never enter
+// the queue on their own.
+func (s *Service) syncSiblings(ctx context.Context, job *core.Job, extraction core.Extraction) {
+	if job.SubIndex() != 0 {
+		return
+	}
+
+	existing, err := s.findSiblings(job)
+	if err != nil {
+		s.logger.Error("Failed to find sibling jobs", zap.String("jobId", job.ID()), zap.Error(err))
+		return
+	}
+
+	collection := job.Record().Collection()
+	taxonomy := s.taxonomy(ctx)
+
+	// Tokens were spent once, on the source job
+	provenance := extraction.Provenance
+	provenance.PromptTokens = 0
+	provenance.CompletionTokens = 0
+
+	for i, data := range extraction.Siblings {
+		subIndex := i + 1
+
+		sibling, ok := existing[subIndex]
+		delete(existing, subIndex)
+		if !ok {
+			sibling = core.NewSiblingJob(collection, job, subIndex)
+		} else if err := restart(sibling); err != nil {
+			s.logger.Warn("Sibling job not updated",
+				zap.String("jobId", sibling.ID()),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		sibling.SetProvenance(provenance)
+		sibling.SetWarnings(nil)
+		if err := sibling.Complete(data, taxonomy); err != nil {
+			continue
+		}
+		s.normalizeSalary(ctx, sibling)
+		s.linkCompany(ctx, sibling)
+
+		if err := s.app.Save(sibling.Record()); err != nil {
+			s.logger.Error("Failed to save sibling job",
+				zap.String("jobId", job.ID()),
+				zap.Int("subIndex", subIndex),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		s.logger.Info("Sibling job processed",
+			zap.String("jobId", sibling.ID()),
+			zap.String("sourceJobId", job.ID()),
+			zap.String("title", data.Title),
+		)
+	}
+
+	for _, stale := range existing {
+		if stale.Status() != core.StatusProcessed || restart(stale) != nil {
+			continue
+		}
+		if err := stale.Reject(reasonVacancyGone); err == nil {
+			s.app.Save(stale.Record())
+		}
+	}
+}
+
+// findSiblings returns the sibling jobs of a source job by sub-index.
+func (s *Service) findSiblings(job *core.Job) (map[int]*core.Job, error) {
+	record := job.Record()
+
+	records, err := s.app.FindRecordsByFilter(
+		"jobs",
+		"channelId = {:channelId} && messageId = {:messageId} && subIndex > 0",
+		"subIndex",
+		0,
+		0,
+		dbx.Params{
+			"channelId": record.GetString("channelId"),
+			"messageId": record.GetInt("messageId"),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	siblings := make(map[int]*core.Job, len(records))
+	for _, r := range records {
+		sibling := core.NewJob(r)
+		siblings[sibling.SubIndex()] = sibling
+	}
+
+	return siblings, nil
+}
+
+// restart moves an existing sibling to processing so it can be completed or
+// rejected again.
+func restart(job *core.Job) error {
+	switch job.Status() {
+	case core.StatusProcessing:
+		return nil
+	case core.StatusRaw:
+	case core.StatusClosed:
+		// The source job was reopened by an edit
+		if err := job.Reopen(); err != nil {
+			return err
+		}
+	case core.StatusWithdrawn:
+		// The source job was restored by a repost
+		if err := job.Restore(); err != nil {
+			return err
+		}
+		if job.Status() != core.StatusRaw {
+			if err := job.Reset(); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := job.Reset(); err != nil {
+			return err
+		}
+	}
+	return job.MarkProcessing()
+}
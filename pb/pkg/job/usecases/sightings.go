@@ -0,0 +1,584 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"go.uber.org/zap"
+
+	"svpb-tmpl/pkg/job/core"
+)
+
+// findCanonical returns the job first collected with the same text, nil if
+// the text is new.
+func (s *Service) findCanonical(input core.RawJobInput) (*core.Job, error) {
+	if input.Hash == "" {
+		return nil, nil
+	}
+
+	records, err := s.app.FindRecordsByFilter(
+		"jobs",
+		"subIndex = 0 && hash = {:hash}",
+		"created",
+		1,
+		0,
+		dbx.Params{"hash": input.Hash},
+	)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	return core.NewJob(records[0]), nil
+}
+
+// findNearDuplicate returns the closest job within the configured distance,
+// the oldest one on ties, nil if none or the text is too short to tell.
+// Candidates are jobs sharing a band of the fingerprint, looked up by the
+// band indexes.
+func (s *Service) findNearDuplicate(input core.RawJobInput) (*core.Job, int, error) {
+	if !s.dedup.NearEnabled || !core.NearMatchable(input.OriginalText) {
+		return nil, 0, nil
+	}
+
+	hash := core.NewSimHash(input.OriginalText)
+	params := dbx.Params{}
+	bands := make([]string, 0, core.SimHashBands)
+	for i := 0; i < core.SimHashBands; i++ {
+		name := fmt.Sprintf("band%d", i)
+		params[name] = hash.Band(i)
+		bands = append(bands, fmt.Sprintf("substr(simhash, %d, 2) = {:%s}", 2*i+1, name))
+	}
+
+	var candidates []struct {
+		ID      string `db:"id"`
+		SimHash string `db:"simhash"`
+	}
+	err := s.app.RecordQuery("jobs").
+		Select("id", "simhash").
+		AndWhere(dbx.NewExp("("+strings.Join(bands, " OR ")+")", params)).
+		AndWhere(dbx.HashExp{"subIndex": 0}).
+		OrderBy("created ASC").
+		All(&candidates)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	bestID, best := "", s.dedup.MaxDistance+1
+	for _, c := range candidates {
+		other, err := core.ParseSimHash(c.SimHash)
+		if err != nil {
+			continue
+		}
+		if d := hash.Distance(other); d < best {
+			bestID, best = c.ID, d
+		}
+	}
+	if bestID == "" {
+		return nil, 0, nil
+	}
+
+	record, err := s.app.FindRecordById("jobs", bestID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return core.NewJob(record), best, nil
+}
+
+// samePrefill reports whether the pre-fill reads the same title, company
+// and salary from a near-duplicate text as from the job it resembles. Both
+// sides are read by the same rules, so it is decided before paying for an
+// extraction and the wording of an LLM title doesn't get in the way.
+func (s *Service) samePrefill(ctx context.Context, job *core.Job, input core.RawJobInput) bool {
+	if s.prefill == nil {
+		return false
+	}
+
+	text := input.ExpandedText
+	if text == "" {
+		text = input.OriginalText
+	}
+
+	repost, err := s.prefill.Extract(ctx, text)
+	if err != nil {
+		return false
+	}
+	original, err := s.prefill.Extract(ctx, job.ExtractionText())
+	if err != nil {
+		return false
+	}
+	return core.SameVacancy(original.Data, repost.Data)
+}
+
+// foldRepost turns a processed near-duplicate the pre-fill couldn't match
+// into a sighting of the job it resembles, if both are the same vacancy. The near-duplicate's sighting is
+// moved over and the job deleted. Returns false if the job was kept.
+func (s *Service) foldRepost(job *core.Job, parsed core.ParsedData, extraction core.Extraction) bool {
+	if job.RepostOf() == "" || job.SubIndex() != 0 || len(extraction.Siblings) > 0 {
+		return false
+	}
+
+	record, err := s.app.FindRecordById("jobs", job.RepostOf())
+	if err != nil {
+		return false
+	}
+	canonical := core.NewJob(record)
+	if !core.SameVacancy(canonical.ParsedData(), parsed) {
+		return false
+	}
+
+	err = s.app.RunInTransaction(func(txApp pbcore.App) error {
+		_, err := txApp.DB().Update(
+			"job_sightings",
+			dbx.Params{"job": canonical.ID(), "distance": job.RepostDistance()},
+			dbx.HashExp{"job": job.ID()},
+		).Execute()
+		if err != nil {
+			return err
+		}
+		return txApp.Delete(job.Record())
+	})
+	if err != nil {
+		s.logger.Error("Failed to fold repost", zap.String("jobId", job.ID()), zap.Error(err))
+		return false
+	}
+
+	if err := s.updateSightings(canonical); err != nil {
+		s.logger.Warn("Sightings not updated", zap.String("jobId", canonical.ID()), zap.Error(err))
+	}
+
+	s.logger.Info("Repost folded into job",
+		zap.String("jobId", canonical.ID()),
+		zap.String("repostId", job.ID()),
+		zap.Int("distance", job.RepostDistance()),
+	)
+
+	return true
+}
+
+// recordSighting stores a repost of a job and refreshes the job's repost
+// counters. Returns false if the message was recorded before.
+func (s *Service) recordSighting(job *core.Job, input core.RawJobInput, distance int) (bool, error) {
+	recorded, err := s.saveSighting(job.ID(), input, distance)
+	if err != nil || !recorded {
+		return false, err
+	}
+	return true, s.updateSightings(job)
+}
+
+// saveSighting stores where and when a job was posted. Returns false if the
+// message was recorded before.
+func (s *Service) saveSighting(jobID string, input core.RawJobInput, distance int) (bool, error) {
+	collection, err := s.app.FindCollectionByNameOrId("job_sightings")
+	if err != nil {
+		return false, fmt.Errorf("job_sightings collection not found: %w", err)
+	}
+
+	seenAt := input.PostedAt
+	if seenAt.IsZero() {
+		seenAt = time.Now()
+	}
+
+	sighting := pbcore.NewRecord(collection)
+	sighting.Set("job", jobID)
+	sighting.Set("channelId", fmt.Sprintf("%d", input.ChannelID))
+	sighting.Set("messageId", input.MessageID)
+	sighting.Set("url", input.URL)
+	sighting.Set("seenAt", seenAt)
+	sighting.Set("distance", distance)
+
+	if err := s.app.Save(sighting); err != nil {
+		// Unique channelId/messageId: the message was recorded already
+		if seen, _ := s.findSighting(input.ChannelID, input.MessageID); seen != "" {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to save sighting: %w", err)
+	}
+
+	return true, nil
+}
+
+// updateSightings recomputes the repost counters of a job from its
+// sightings and saves them on the job and its siblings. A repost brings a
+// withdrawn job back.
+func (s *Service) updateSightings(job *core.Job) error {
+	// Reload so a concurrent processing of the job is not overwritten
+	record, err := s.app.FindRecordById("jobs", job.ID())
+	if err != nil {
+		return fmt.Errorf("job not found: %w", err)
+	}
+	job = core.NewJob(record)
+
+	stats, err := s.sightingStats(job.ID())
+	if err != nil {
+		return err
+	}
+
+	job.SetSightings(stats)
+	if job.Restore() == nil {
+		s.logger.Info("Withdrawn job reposted, restoring", zap.String("jobId", job.ID()))
+	}
+	if err := s.app.Save(job.Record()); err != nil {
+		return fmt.Errorf("failed to save job sightings: %w", err)
+	}
+
+	siblings, err := s.findSiblings(job)
+	if err != nil {
+		return fmt.Errorf("failed to find sibling jobs: %w", err)
+	}
+	for _, sibling := range siblings {
+		sibling.SetSightings(stats)
+		sibling.Restore()
+		if err := s.app.Save(sibling.Record()); err != nil {
+			return fmt.Errorf("failed to save sibling sightings: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// refreshSightings loads the current repost counters into a job that is
+// about to be saved. Jobs without sightings keep their own counters.
+func (s *Service) refreshSightings(job *core.Job) {
+	stats, err := s.sightingStats(job.ID())
+	if err != nil {
+		s.logger.Warn("Sightings not loaded", zap.String("jobId", job.ID()), zap.Error(err))
+		return
+	}
+	if stats.Count > 0 {
+		job.SetSightings(stats)
+	}
+}
+
+func (s *Service) sightingStats(jobID string) (core.SightingStats, error) {
+	var stats core.SightingStats
+
+	err := s.app.DB().NewQuery(
+		"SELECT COUNT(*) AS count, COALESCE(MIN(seenAt), '') AS firstSeen, COALESCE(MAX(seenAt), '') AS lastSeen " +
+			"FROM job_sightings WHERE job = {:job}",
+	).Bind(dbx.Params{"job": jobID}).One(&stats)
+	if err != nil {
+		return stats, fmt.Errorf("failed to count sightings: %w", err)
+	}
+
+	return stats, nil
+}
+
+// findMessageJob returns the job a message was collected as, directly, as
+// part of an album or as a sighting, empty if the message is new.
+func (s *Service) findMessageJob(channelID int64, messageID int, albumID int64) (string, error) {
+	record, err := s.findMessageRecord(channelID, messageID, albumID)
+	if err == nil {
+		return record.Id, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	return s.findSighting(channelID, messageID)
+}
+
+// findMessageRecord returns the job a message was collected as, or the job
+// of the album it is part of. Returns sql.ErrNoRows if there is none.
+func (s *Service) findMessageRecord(channelID int64, messageID int, albumID int64) (*pbcore.Record, error) {
+	var records []*pbcore.Record
+	err := s.app.RecordQuery("jobs").
+		AndWhere(dbx.NewExp(`subIndex = 0 AND channelId = {:channelId} AND (
+			messageId = {:messageId} OR (albumId != '' AND (
+				albumId = {:albumId} OR
+				EXISTS (SELECT 1 FROM json_each(jobs.albumMessageIds) WHERE json_each.value = {:messageId})
+			))
+		)`, dbx.Params{
+			"channelId": fmt.Sprintf("%d", channelID),
+			"messageId": messageID,
+			"albumId":   strconv.FormatInt(albumID, 10),
+		})).
+		All(&records)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	// The message's own job comes before an album it was later merged into
+	for _, record := range records {
+		if record.GetInt("messageId") == messageID {
+			return record, nil
+		}
+	}
+	return records[0], nil
+}
+
+// findSighting returns the job a message was recorded as a sighting of.
+func (s *Service) findSighting(channelID int64, messageID int) (string, error) {
+	var jobID string
+
+	err := s.app.DB().NewQuery(
+		"SELECT job FROM job_sightings WHERE channelId = {:channelId} AND messageId = {:messageId}",
+	).Bind(dbx.Params{
+		"channelId": fmt.Sprintf("%d", channelID),
+		"messageId": messageID,
+	}).Row(&jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	return jobID, err
+}
+
+// Sightings returns where and when a job was posted, oldest first.
+// Siblings of a multi-vacancy post share the sightings of their source job.
+func (s *Service) Sightings(ctx context.Context, jobID string) ([]core.Sighting, error) {
+	record, err := s.app.FindRecordById("jobs", jobID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", core.ErrJobNotFound, jobID)
+	}
+
+	job := core.NewJob(record)
+	if job.SubIndex() > 0 {
+		source, err := s.app.FindFirstRecordByFilter(
+			"jobs",
+			"channelId = {:channelId} && messageId = {:messageId} && subIndex = 0",
+			dbx.Params{
+				"channelId": record.GetString("channelId"),
+				"messageId": record.GetInt("messageId"),
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("source job not found: %w", err)
+		}
+		jobID = source.Id
+	}
+
+	sightings := []core.Sighting{}
+	err = s.app.RecordQuery("job_sightings").
+		Select("id", "job", "channelId", "messageId", "url", "seenAt", "distance", "deletedAt").
+		AndWhere(dbx.HashExp{"job": jobID}).
+		OrderBy("seenAt ASC").
+		All(&sightings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sightings: %w", err)
+	}
+
+	return sightings, nil
+}
+
+// Fingerprint computes SimHash fingerprints of jobs collected without one.
+func (s *Service) Fingerprint(ctx context.Context) (int, error) {
+	records, err := s.app.FindRecordsByFilter("jobs", "simhash = ''", "", 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find jobs: %w", err)
+	}
+
+	updated := 0
+	for _, record := range records {
+		job := core.NewJob(record)
+		job.Fingerprint()
+
+		if err := s.app.Save(job.Record()); err != nil {
+			return updated, fmt.Errorf("failed to save job %s: %w", job.ID(), err)
+		}
+		updated++
+	}
+
+	s.logger.Info("Jobs fingerprinted", zap.Int("updated", updated))
+
+	return updated, nil
+}
+
+// Albums returns the messages of the album jobs the given messages are
+// part of.
+func (s *Service) Albums(ctx context.Context, channelID int64, messageIDs []int) ([][]int, error) {
+	records, err := s.findAlbumRecords(channelID, messageIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find albums: %w", err)
+	}
+
+	albums := make([][]int, 0, len(records))
+	for _, record := range records {
+		albums = append(albums, core.NewJob(record).AlbumMessageIDs())
+	}
+	return albums, nil
+}
+
+// findAlbumRecords returns the album jobs any of the messages is part of.
+func (s *Service) findAlbumRecords(channelID int64, messageIDs []int) ([]*pbcore.Record, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+
+	params := dbx.Params{"channelId": fmt.Sprintf("%d", channelID)}
+	placeholders := make([]string, 0, len(messageIDs))
+	for i, id := range messageIDs {
+		name := fmt.Sprintf("messageId%d", i)
+		params[name] = id
+		placeholders = append(placeholders, "{:"+name+"}")
+	}
+
+	var records []*pbcore.Record
+	err := s.app.RecordQuery("jobs").
+		AndWhere(dbx.NewExp(`subIndex = 0 AND channelId = {:channelId} AND albumId != '' AND
+			EXISTS (SELECT 1 FROM json_each(jobs.albumMessageIds) WHERE json_each.value IN (`+
+			strings.Join(placeholders, ", ")+`))`, params)).
+		All(&records)
+	return records, err
+}
+
+// Withdraw marks the sightings of deleted messages and withdraws jobs left
+// without a posted message, along with their siblings. Deleted parts of an
+// album leave the album; its job is withdrawn once none is left.
+func (s *Service) Withdraw(ctx context.Context, channelID int64, messageIDs []int) (int, error) {
+	if len(messageIDs) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]any, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		ids = append(ids, id)
+	}
+	where := dbx.And(
+		dbx.HashExp{"channelId": fmt.Sprintf("%d", channelID)},
+		dbx.In("messageId", ids...),
+	)
+
+	var jobIDs []string
+	err := s.app.DB().
+		Select("job").
+		Distinct(true).
+		From("job_sightings").
+		Where(where).
+		Column(&jobIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find deleted sightings: %w", err)
+	}
+
+	now := types.NowDateTime()
+	if len(jobIDs) > 0 {
+		_, err = s.app.DB().
+			Update("job_sightings", dbx.Params{"deletedAt": now.String()}, dbx.And(where, dbx.HashExp{"deletedAt": ""})).
+			Execute()
+		if err != nil {
+			return 0, fmt.Errorf("failed to mark deleted sightings: %w", err)
+		}
+	}
+
+	albums, err := s.findAlbumRecords(channelID, messageIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find deleted album messages: %w", err)
+	}
+	for _, record := range albums {
+		job := core.NewJob(record)
+		if !job.RemoveAlbumMessages(messageIDs) {
+			continue
+		}
+		if err := s.app.Save(job.Record()); err != nil {
+			return 0, fmt.Errorf("failed to save album job: %w", err)
+		}
+		if !slices.Contains(jobIDs, job.ID()) {
+			jobIDs = append(jobIDs, job.ID())
+		}
+	}
+
+	withdrawn := 0
+	for _, jobID := range jobIDs {
+		ok, err := s.withdrawJob(jobID, now.Time())
+		if err != nil {
+			s.logger.Error("Failed to withdraw job", zap.String("jobId", jobID), zap.Error(err))
+			continue
+		}
+		if ok {
+			withdrawn++
+		}
+	}
+
+	return withdrawn, nil
+}
+
+// withdrawJob withdraws a job and its siblings unless one of its posts is
+// still there. Returns false if the job was kept.
+func (s *Service) withdrawJob(jobID string, now time.Time) (bool, error) {
+	posted, _, err := s.countPosts(jobID)
+	if err != nil {
+		return false, err
+	}
+	if posted > 0 {
+		s.logger.Debug("Deleted post of job still posted elsewhere", zap.String("jobId", jobID), zap.Int("posted", posted))
+		return false, nil
+	}
+
+	record, err := s.app.FindRecordById("jobs", jobID)
+	if err != nil {
+		return false, fmt.Errorf("job not found: %w", err)
+	}
+
+	job := core.NewJob(record)
+	if remaining := len(job.AlbumMessageIDs()); remaining > 0 {
+		s.logger.Debug("Deleted post of album still partly posted", zap.String("jobId", jobID), zap.Int("remaining", remaining))
+		return false, nil
+	}
+	if err := job.Withdraw(now); err != nil {
+		// Rejected and closed jobs keep their status. Jobs being processed
+		// are withdrawn once done, see withdrawDeleted.
+		return false, nil
+	}
+	if err := s.app.Save(job.Record()); err != nil {
+		return false, fmt.Errorf("failed to save withdrawn job: %w", err)
+	}
+
+	siblings, err := s.findSiblings(job)
+	if err != nil {
+		return true, fmt.Errorf("failed to find sibling jobs: %w", err)
+	}
+	for _, sibling := range siblings {
+		if sibling.Withdraw(now) != nil {
+			continue
+		}
+		if err := s.app.Save(sibling.Record()); err != nil {
+			return true, fmt.Errorf("failed to save withdrawn sibling job: %w", err)
+		}
+	}
+
+	s.logger.Info("Job withdrawn", zap.String("jobId", jobID))
+
+	return true, nil
+}
+
+// withdrawDeleted withdraws a job whose posts were all deleted while it was
+// being processed.
+func (s *Service) withdrawDeleted(job *core.Job) {
+	posted, deleted, err := s.countPosts(job.ID())
+	if err != nil {
+		s.logger.Warn("Deleted posts not checked", zap.String("jobId", job.ID()), zap.Error(err))
+		return
+	}
+	if posted > 0 || deleted == 0 {
+		return
+	}
+
+	if _, err := s.withdrawJob(job.ID(), time.Now()); err != nil {
+		s.logger.Error("Failed to withdraw job", zap.String("jobId", job.ID()), zap.Error(err))
+	}
+}
+
+// countPosts counts the sightings of a job still posted and deleted.
+func (s *Service) countPosts(jobID string) (posted, deleted int, err error) {
+	err = s.app.DB().
+		Select("COUNT(CASE WHEN deletedAt = '' THEN 1 END)", "COUNT(CASE WHEN deletedAt != '' THEN 1 END)").
+		From("job_sightings").
+		Where(dbx.HashExp{"job": jobID}).
+		Row(&posted, &deleted)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count sightings: %w", err)
+	}
+	return posted, deleted, nil
+}
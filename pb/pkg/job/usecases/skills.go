@@ -0,0 +1,274 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"go.uber.org/zap"
+
+	"svpb-tmpl/pkg/job/core"
+)
+
+const skillsCollection = "skills"
+
+// Skills implements core.SkillService on top of the skills collection.
+type Skills struct {
+	app    *pocketbase.PocketBase
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	taxonomy *core.SkillTaxonomy
+}
+
+// NewSkills creates a new SkillService implementation.
+func NewSkills(app *pocketbase.PocketBase, logger *zap.Logger) *Skills {
+	return &Skills{
+		app:    app,
+		logger: logger,
+	}
+}
+
+// Taxonomy returns the cached taxonomy, loading it on first use.
+func (s *Skills) Taxonomy(ctx context.Context) (*core.SkillTaxonomy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.taxonomy != nil {
+		return s.taxonomy, nil
+	}
+
+	skills, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.taxonomy = core.NewSkillTaxonomy(skills)
+	return s.taxonomy, nil
+}
+
+// Invalidate drops the cached taxonomy.
+func (s *Skills) Invalidate() {
+	s.mu.Lock()
+	s.taxonomy = nil
+	s.mu.Unlock()
+}
+
+// List returns all canonical skills ordered by name.
+func (s *Skills) List(ctx context.Context) ([]core.Skill, error) {
+	records, err := s.app.FindRecordsByFilter(skillsCollection, "", "name", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list skills: %w", err)
+	}
+
+	skills := make([]core.Skill, 0, len(records))
+	for _, record := range records {
+		skills = append(skills, toSkill(record))
+	}
+
+	return skills, nil
+}
+
+// Add creates a skill or adds aliases to an existing one with the same name.
+func (s *Skills) Add(ctx context.Context, skill core.Skill) error {
+	record, err := findSkill(s.app, skill.Name)
+	if err != nil {
+		collection, err := s.app.FindCollectionByNameOrId(skillsCollection)
+		if err != nil {
+			return fmt.Errorf("skills collection not found: %w", err)
+		}
+		record = pbcore.NewRecord(collection)
+		record.Set("name", skill.Name)
+	}
+
+	if skill.Category != "" {
+		record.Set("category", string(skill.Category))
+	}
+	addAliases(record, skill.Aliases...)
+
+	if err := s.app.Save(record); err != nil {
+		return fmt.Errorf("failed to save skill %q: %w", skill.Name, err)
+	}
+
+	s.Invalidate()
+	return nil
+}
+
+// Merge folds sources into target and relinks jobs.
+func (s *Skills) Merge(ctx context.Context, target string, sources []string) (int, error) {
+	err := s.app.RunInTransaction(func(txApp pbcore.App) error {
+		targetRecord, err := findSkill(txApp, target)
+		if err != nil {
+			return fmt.Errorf("skill %q not found: %w", target, err)
+		}
+
+		for _, source := range sources {
+			addAliases(targetRecord, source)
+
+			// Sources may also be plain spellings that are not skills yet
+			sourceRecord, err := findSkill(txApp, source)
+			if err != nil || sourceRecord.Id == targetRecord.Id {
+				continue
+			}
+
+			addAliases(targetRecord, toSkill(sourceRecord).Aliases...)
+			if err := txApp.Delete(sourceRecord); err != nil {
+				return fmt.Errorf("failed to delete skill %q: %w", source, err)
+			}
+		}
+
+		return txApp.Save(targetRecord)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.Invalidate()
+	return s.Relink(ctx)
+}
+
+// Split moves aliases of source to into and relinks jobs.
+func (s *Skills) Split(ctx context.Context, source string, aliases []string, into core.Skill) (int, error) {
+	err := s.app.RunInTransaction(func(txApp pbcore.App) error {
+		sourceRecord, err := findSkill(txApp, source)
+		if err != nil {
+			return fmt.Errorf("skill %q not found: %w", source, err)
+		}
+
+		moved := make(map[string]bool, len(aliases))
+		for _, alias := range aliases {
+			moved[core.SkillKey(alias)] = true
+		}
+
+		remaining := slices.DeleteFunc(toSkill(sourceRecord).Aliases, func(alias string) bool {
+			return moved[core.SkillKey(alias)]
+		})
+		sourceRecord.Set("aliases", remaining)
+		if err := txApp.Save(sourceRecord); err != nil {
+			return err
+		}
+
+		intoRecord, err := findSkill(txApp, into.Name)
+		if err != nil {
+			collection, err := txApp.FindCollectionByNameOrId(skillsCollection)
+			if err != nil {
+				return err
+			}
+			intoRecord = pbcore.NewRecord(collection)
+			intoRecord.Set("name", into.Name)
+			intoRecord.Set("category", sourceRecord.GetString("category"))
+		}
+		if into.Category != "" {
+			intoRecord.Set("category", string(into.Category))
+		}
+		addAliases(intoRecord, aliases...)
+
+		return txApp.Save(intoRecord)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.Invalidate()
+	return s.Relink(ctx)
+}
+
+// Relink re-resolves skills of all processed jobs.
+func (s *Skills) Relink(ctx context.Context) (int, error) {
+	taxonomy, err := s.Taxonomy(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	records, err := s.app.FindRecordsByFilter("jobs", "status = 'processed'", "", 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find jobs: %w", err)
+	}
+
+	updated := 0
+	for _, record := range records {
+		before := core.NewJob(record).ParsedData().Skills
+		beforeIDs := record.GetStringSlice("canonicalSkills")
+
+		job := core.NewJob(record)
+		job.ResolveSkills(taxonomy)
+
+		if slices.Equal(beforeIDs, record.GetStringSlice("canonicalSkills")) &&
+			slices.Equal(before, job.ParsedData().Skills) {
+			continue
+		}
+
+		if err := s.app.Save(record); err != nil {
+			return updated, fmt.Errorf("failed to save job %s: %w", record.Id, err)
+		}
+		updated++
+	}
+
+	s.logger.Info("Job skills relinked", zap.Int("updated", updated))
+	return updated, nil
+}
+
+// Facets counts processed jobs per canonical skill, most common first.
+func (s *Skills) Facets(ctx context.Context) ([]core.SkillFacet, error) {
+	var facets []core.SkillFacet
+
+	err := s.app.DB().
+		Select("s.id AS id", "s.name AS name", "s.category AS category", "COUNT(*) AS count").
+		From("jobs j").
+		InnerJoin("json_each(j.canonicalSkills) je", nil).
+		InnerJoin("skills s", dbx.NewExp("s.id = je.value")).
+		Where(dbx.HashExp{"j.status": string(core.StatusProcessed)}).
+		GroupBy("s.id").
+		OrderBy("count DESC", "s.name").
+		WithContext(ctx).
+		All(&facets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count skill facets: %w", err)
+	}
+
+	return facets, nil
+}
+
+// findSkill looks a skill up by name, case-insensitively.
+func findSkill(app pbcore.App, name string) (*pbcore.Record, error) {
+	return app.FindFirstRecordByFilter(skillsCollection, "name:lower = {:name}", dbx.Params{
+		"name": strings.ToLower(name),
+	})
+}
+
+// addAliases appends new spellings, skipping ones equal to the name or
+// already present.
+func addAliases(record *pbcore.Record, aliases ...string) {
+	skill := toSkill(record)
+
+	seen := map[string]bool{core.SkillKey(skill.Name): true}
+	for _, alias := range skill.Aliases {
+		seen[core.SkillKey(alias)] = true
+	}
+
+	for _, alias := range aliases {
+		if key := core.SkillKey(alias); key != "" && !seen[key] {
+			seen[key] = true
+			skill.Aliases = append(skill.Aliases, key)
+		}
+	}
+
+	record.Set("aliases", skill.Aliases)
+}
+
+func toSkill(record *pbcore.Record) core.Skill {
+	var aliases []string
+	_ = record.UnmarshalJSONField("aliases", &aliases)
+
+	return core.Skill{
+		ID:       record.Id,
+		Name:     record.GetString("name"),
+		Category: core.SkillCategory(record.GetString("category")),
+		Aliases:  aliases,
+	}
+}
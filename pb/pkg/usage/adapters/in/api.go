@@ -0,0 +1,92 @@
+package in
+
+import (
+	"strings"
+	"time"
+
+	"svpb-tmpl/pkg/usage/core"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"go.uber.org/zap"
+)
+
+const dateLayout = "2006-01-02"
+
+// API handles HTTP requests for the usage module.
+type API struct {
+	service core.UsageService
+	logger  *zap.Logger
+}
+
+// NewAPI creates a new API adapter.
+func NewAPI(service core.UsageService, logger *zap.Logger) *API {
+	return &API{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Register registers all HTTP routes on the PocketBase app.
+func (a *API) Register(app *pocketbase.PocketBase) {
+	app.OnServe().BindFunc(func(se *pbcore.ServeEvent) error {
+		se.Router.GET("/api/llm-usage", a.handleSummary).Bind(apis.RequireSuperuserAuth())
+		se.Router.GET("/api/llm-usage/budget", a.handleBudget).Bind(apis.RequireSuperuserAuth())
+		return se.Next()
+	})
+}
+
+// handleSummary aggregates usage, e.g.
+// GET /api/llm-usage?groupBy=day,stage&from=2026-01-01&to=2026-02-01
+func (a *API) handleSummary(e *pbcore.RequestEvent) error {
+	params := e.Request.URL.Query()
+
+	query := core.SummaryQuery{
+		GroupBy: splitList(params.Get("groupBy")),
+	}
+
+	var err error
+	if from := params.Get("from"); from != "" {
+		if query.From, err = time.Parse(dateLayout, from); err != nil {
+			return e.BadRequestError("Invalid from date", err)
+		}
+	}
+	if to := params.Get("to"); to != "" {
+		if query.To, err = time.Parse(dateLayout, to); err != nil {
+			return e.BadRequestError("Invalid to date", err)
+		}
+	}
+
+	rows, err := a.service.Summarize(e.Request.Context(), query)
+	if err != nil {
+		return e.BadRequestError("Failed to summarize usage", err)
+	}
+
+	return e.JSON(200, rows)
+}
+
+// handleBudget reports today's spend per stage against the caps.
+func (a *API) handleBudget(e *pbcore.RequestEvent) error {
+	var statuses []core.BudgetStatus
+
+	for _, stage := range []core.Stage{core.StageExtraction, core.StageOffer} {
+		status, err := a.service.Budget(e.Request.Context(), stage)
+		if err != nil {
+			return e.InternalServerError("Failed to read budget", err)
+		}
+		statuses = append(statuses, status)
+	}
+
+	return e.JSON(200, statuses)
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,52 @@
+package in
+
+import (
+	"context"
+
+	"svpb-tmpl/infra/llm"
+	"svpb-tmpl/pkg/usage/core"
+
+	"go.uber.org/zap"
+)
+
+// Meter wraps an llm.Completer and records every successful call of a stage.
+// User and job attribution is taken from the context (core.WithUser, core.WithJob).
+type Meter struct {
+	llm     llm.Completer
+	stage   core.Stage
+	service core.UsageService
+	logger  *zap.Logger
+}
+
+// NewMeter creates a metered completer for a stage.
+func NewMeter(completer llm.Completer, stage core.Stage, service core.UsageService, logger *zap.Logger) *Meter {
+	return &Meter{
+		llm:     completer,
+		stage:   stage,
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Complete implements llm.Completer.
+func (m *Meter) Complete(ctx context.Context, req llm.Request) (llm.Response, error) {
+	resp, err := m.llm.Complete(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	// Recording must not fail a call that was already paid for
+	if err := m.service.Record(context.WithoutCancel(ctx), core.Call{
+		Stage:            m.stage,
+		Provider:         resp.Provider,
+		Model:            resp.Model,
+		PromptTokens:     resp.PromptTokens,
+		CompletionTokens: resp.CompletionTokens,
+		UserID:           core.UserFrom(ctx),
+		JobID:            core.JobFrom(ctx),
+	}); err != nil {
+		m.logger.Error("Failed to record LLM usage", zap.Error(err), zap.String("stage", string(m.stage)))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,29 @@
+package core
+
+import "context"
+
+type userKey struct{}
+
+type jobKey struct{}
+
+// WithUser attributes LLM calls made with ctx to a user.
+func WithUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// WithJob attributes LLM calls made with ctx to a job.
+func WithJob(ctx context.Context, jobID string) context.Context {
+	return context.WithValue(ctx, jobKey{}, jobID)
+}
+
+// UserFrom returns the user set by WithUser, if any.
+func UserFrom(ctx context.Context) string {
+	id, _ := ctx.Value(userKey{}).(string)
+	return id
+}
+
+// JobFrom returns the job set by WithJob, if any.
+func JobFrom(ctx context.Context) string {
+	id, _ := ctx.Value(jobKey{}).(string)
+	return id
+}
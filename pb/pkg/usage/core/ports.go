@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Stage identifies the pipeline step an LLM call belongs to.
+type Stage string
+
+const (
+	// StageExtraction is background vacancy extraction.
+	StageExtraction Stage = "extraction"
+	// StageOffer is interactive offer generation.
+	StageOffer Stage = "offer"
+)
+
+// ErrBudgetExceeded is returned when a stage has spent its daily budget.
+var ErrBudgetExceeded = errors.New("daily LLM budget exceeded")
+
+// Call is a single completed LLM call.
+type Call struct {
+	Stage            Stage
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	UserID           string
+	JobID            string
+}
+
+// SummaryQuery selects and groups usage for reporting.
+// Zero From/To are ignored.
+type SummaryQuery struct {
+	From time.Time
+	To   time.Time
+	// GroupBy lists dimensions: "day", "user", "stage", "model".
+	GroupBy []string
+}
+
+// SummaryRow is aggregated usage for one group.
+// Dimensions not in SummaryQuery.GroupBy are left empty.
+type SummaryRow struct {
+	Day              string  `db:"day" json:"day,omitempty"`
+	UserID           string  `db:"user" json:"userId,omitempty"`
+	Stage            string  `db:"stage" json:"stage,omitempty"`
+	Model            string  `db:"model" json:"model,omitempty"`
+	Calls            int     `db:"calls" json:"calls"`
+	PromptTokens     int     `db:"promptTokens" json:"promptTokens"`
+	CompletionTokens int     `db:"completionTokens" json:"completionTokens"`
+	Cost             float64 `db:"cost" json:"cost"`
+}
+
+// BudgetStatus is today's spend of a stage against its cap.
+type BudgetStatus struct {
+	Stage Stage   `json:"stage"`
+	Spent float64 `json:"spent"`
+	// Limit is 0 when the stage is unlimited.
+	Limit    float64 `json:"limit"`
+	Exceeded bool    `json:"exceeded"`
+}
+
+// UsageService records LLM usage and enforces daily budgets.
+type UsageService interface {
+	// Record stores a call with its computed cost.
+	Record(ctx context.Context, call Call) error
+
+	// Summarize aggregates usage by the requested dimensions.
+	Summarize(ctx context.Context, query SummaryQuery) ([]SummaryRow, error)
+
+	// Budget returns today's spend of a stage against its cap.
+	Budget(ctx context.Context, stage Stage) (BudgetStatus, error)
+
+	// CheckBudget returns ErrBudgetExceeded if the stage may not spend more today.
+	CheckBudget(ctx context.Context, stage Stage) error
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// PriceTable maps "provider:model" to prices. The model part is matched as a
+// prefix of the reported model name, so "openai:gpt-5-nano" also prices
+// "gpt-5-nano-2025-08-07". An empty model ("ollama:") prices every model of
+// the provider.
+type PriceTable map[string]Price
+
+// DefaultPrices covers the models used by default. Local providers are free.
+func DefaultPrices() PriceTable {
+	return PriceTable{
+		"openai:gpt-5":                {Input: 1.25, Output: 10},
+		"openai:gpt-5-mini":           {Input: 0.25, Output: 2},
+		"openai:gpt-5-nano":           {Input: 0.05, Output: 0.40},
+		"openai:gpt-5.2":              {Input: 1.75, Output: 14},
+		"openai:gpt-4o":               {Input: 2.50, Output: 10},
+		"openai:gpt-4o-mini":          {Input: 0.15, Output: 0.60},
+		"anthropic:claude-sonnet-4-5": {Input: 3, Output: 15},
+		"anthropic:claude-haiku-4-5":  {Input: 1, Output: 5},
+		"ollama:":                     {},
+		"llamacpp:":                   {},
+	}
+}
+
+// ParsePrices parses "provider:model=input/output" specs on top of the
+// defaults, e.g. "openai:gpt-5-nano=0.05/0.40".
+func ParsePrices(specs []string) (PriceTable, error) {
+	table := DefaultPrices()
+
+	for _, spec := range specs {
+		key, value, ok := strings.Cut(spec, "=")
+		in, out, ok2 := strings.Cut(value, "/")
+		if !ok || !ok2 || !strings.Contains(key, ":") {
+			return nil, fmt.Errorf("invalid price spec %q, expected provider:model=input/output", spec)
+		}
+
+		input, err := strconv.ParseFloat(strings.TrimSpace(in), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input price in %q: %w", spec, err)
+		}
+		output, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output price in %q: %w", spec, err)
+		}
+
+		table[strings.TrimSpace(key)] = Price{Input: input, Output: output}
+	}
+
+	return table, nil
+}
+
+// Lookup returns the price with the longest matching model prefix.
+func (t PriceTable) Lookup(provider, model string) (Price, bool) {
+	var (
+		best    Price
+		bestLen = -1
+	)
+
+	for key, price := range t {
+		p, m, _ := strings.Cut(key, ":")
+		if p == provider && strings.HasPrefix(model, m) && len(m) > bestLen {
+			best, bestLen = price, len(m)
+		}
+	}
+
+	return best, bestLen >= 0
+}
+
+// Cost returns the USD cost of a call and whether its model is priced.
+func (t PriceTable) Cost(call Call) (float64, bool) {
+	price, ok := t.Lookup(call.Provider, call.Model)
+	if !ok {
+		return 0, false
+	}
+
+	return (float64(call.PromptTokens)*price.Input + float64(call.CompletionTokens)*price.Output) / 1_000_000, true
+}
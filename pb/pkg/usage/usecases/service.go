@@ -0,0 +1,192 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	pbcore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"go.uber.org/zap"
+
+	"svpb-tmpl/config"
+	"svpb-tmpl/pkg/usage/core"
+)
+
+const usageCollection = "llm_usage"
+
+// groupColumns maps SummaryQuery.GroupBy dimensions to SQL expressions.
+var groupColumns = map[string]string{
+	"day":   "substr([[created]], 1, 10)",
+	"user":  "[[user]]",
+	"stage": "[[stage]]",
+	"model": "[[provider]] || ':' || [[model]]",
+}
+
+// Service implements core.UsageService on top of the llm_usage collection.
+type Service struct {
+	app    *pocketbase.PocketBase
+	prices core.PriceTable
+	cfg    config.UsageConfig
+	logger *zap.Logger
+
+	unpriced sync.Map
+}
+
+// NewService creates a new UsageService implementation.
+func NewService(
+	app *pocketbase.PocketBase,
+	prices core.PriceTable,
+	cfg config.UsageConfig,
+	logger *zap.Logger,
+) *Service {
+	return &Service{
+		app:    app,
+		prices: prices,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Record stores a call with its computed cost.
+func (s *Service) Record(ctx context.Context, call core.Call) error {
+	collection, err := s.app.FindCollectionByNameOrId(usageCollection)
+	if err != nil {
+		return fmt.Errorf("llm_usage collection not found: %w", err)
+	}
+
+	cost, ok := s.prices.Cost(call)
+	if !ok {
+		// Warn once per model, the call is still recorded at zero cost
+		if _, seen := s.unpriced.LoadOrStore(call.Provider+":"+call.Model, true); !seen {
+			s.logger.Warn("No price for LLM model, recording zero cost",
+				zap.String("provider", call.Provider),
+				zap.String("model", call.Model),
+			)
+		}
+	}
+
+	record := pbcore.NewRecord(collection)
+	record.Set("stage", string(call.Stage))
+	record.Set("provider", call.Provider)
+	record.Set("model", call.Model)
+	record.Set("promptTokens", call.PromptTokens)
+	record.Set("completionTokens", call.CompletionTokens)
+	record.Set("cost", cost)
+	record.Set("user", call.UserID)
+	record.Set("job", call.JobID)
+
+	if err := s.app.Save(record); err != nil {
+		return fmt.Errorf("failed to record llm usage: %w", err)
+	}
+
+	return nil
+}
+
+// Summarize aggregates usage by the requested dimensions.
+func (s *Service) Summarize(ctx context.Context, query core.SummaryQuery) ([]core.SummaryRow, error) {
+	columns := []string{
+		"COUNT(*) AS calls",
+		"COALESCE(SUM([[promptTokens]]), 0) AS promptTokens",
+		"COALESCE(SUM([[completionTokens]]), 0) AS completionTokens",
+		"COALESCE(SUM([[cost]]), 0) AS cost",
+	}
+
+	var groups []string
+	for _, dim := range query.GroupBy {
+		expr, ok := groupColumns[dim]
+		if !ok {
+			return nil, fmt.Errorf("unknown group by dimension %q", dim)
+		}
+		columns = append(columns, expr+" AS "+dim)
+		groups = append(groups, dim)
+	}
+
+	q := s.app.DB().Select(columns...).From(usageCollection)
+
+	if !query.From.IsZero() {
+		q.AndWhere(dbx.NewExp("[[created]] >= {:from}", dbx.Params{
+			"from": query.From.UTC().Format(types.DefaultDateLayout),
+		}))
+	}
+	if !query.To.IsZero() {
+		q.AndWhere(dbx.NewExp("[[created]] < {:to}", dbx.Params{
+			"to": query.To.UTC().Format(types.DefaultDateLayout),
+		}))
+	}
+
+	if len(groups) > 0 {
+		q.GroupBy(groups...).OrderBy(groups...)
+	}
+
+	var rows []core.SummaryRow
+	if err := q.WithContext(ctx).All(&rows); err != nil {
+		return nil, fmt.Errorf("failed to summarize llm usage: %w", err)
+	}
+
+	return rows, nil
+}
+
+// Budget returns today's (UTC) spend of a stage against its cap.
+func (s *Service) Budget(ctx context.Context, stage core.Stage) (core.BudgetStatus, error) {
+	status := core.BudgetStatus{
+		Stage: stage,
+		Limit: s.limit(stage),
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	var row struct {
+		Spent float64 `db:"spent"`
+	}
+
+	err := s.app.DB().
+		Select("COALESCE(SUM([[cost]]), 0) AS spent").
+		From(usageCollection).
+		Where(dbx.HashExp{"stage": string(stage)}).
+		AndWhere(dbx.NewExp("[[created]] >= {:today}", dbx.Params{
+			"today": today.Format(types.DefaultDateLayout),
+		})).
+		WithContext(ctx).
+		One(&row)
+	if err != nil {
+		return status, fmt.Errorf("failed to read %s spend: %w", stage, err)
+	}
+
+	status.Spent = row.Spent
+	status.Exceeded = status.Limit > 0 && status.Spent >= status.Limit
+
+	return status, nil
+}
+
+// CheckBudget returns core.ErrBudgetExceeded if the stage may not spend more today.
+func (s *Service) CheckBudget(ctx context.Context, stage core.Stage) error {
+	if s.limit(stage) == 0 {
+		return nil
+	}
+
+	status, err := s.Budget(ctx, stage)
+	if err != nil {
+		return err
+	}
+
+	if status.Exceeded {
+		return fmt.Errorf("%w: %s spent $%.4f of $%.4f", core.ErrBudgetExceeded, stage, status.Spent, status.Limit)
+	}
+
+	return nil
+}
+
+// limit returns the daily cap of a stage, 0 if unlimited.
+func (s *Service) limit(stage core.Stage) float64 {
+	switch stage {
+	case core.StageExtraction:
+		return s.cfg.ExtractionDailyBudget
+	case core.StageOffer:
+		return s.cfg.OfferDailyBudget
+	}
+	return 0
+}
@@ -1,7 +1,12 @@
-import { Collections, pb, type JobsResponse } from '$lib';
+import { Collections, JobsStatusOptions, pb, type JobsResponse } from '$lib';
 
 import { userJobsStore } from './user-jobs.svelte';
 
+// monthlySalary returns the best comparable monthly pay of a job, 0 if unknown.
+function monthlySalary(job: JobsResponse) {
+	return Math.max(job.salaryMaxMonthly || 0, job.salaryMinMonthly || 0);
+}
+
 class JobsStore {
 	private userId: string | null = null;
 
@@ -9,6 +14,9 @@ class JobsStore {
 	search = $state('');
 	filterRemote: boolean | null = $state(null);
 	filterGrade = $state('');
+	// Minimum monthly salary in the base currency
+	filterMinSalary: number | null = $state(null);
+	sortBy: 'date' | 'salary' = $state('date');
 	showArchived = $state(false);
 
 	currentPage = $state(1);
@@ -44,6 +52,15 @@ class JobsStore {
 			);
 		}
 
+		if (this.filterMinSalary) {
+			const min = this.filterMinSalary;
+			result = result.filter((j) => monthlySalary(j) >= min);
+		}
+
+		if (this.sortBy === 'salary') {
+			result = [...result].sort((a, b) => monthlySalary(b) - monthlySalary(a));
+		}
+
 		return result;
 	});
 
@@ -58,7 +75,14 @@ class JobsStore {
 	constructor() {
 		$effect.root(() => {
 			$effect(() => {		
-				void [this.search, this.filterRemote, this.filterGrade, this.showArchived];
+				void [
+					this.search,
+					this.filterRemote,
+					this.filterGrade,
+					this.filterMinSalary,
+					this.sortBy,
+					this.showArchived
+				];
 				this.currentPage = 1;
 			});
 		});
@@ -93,6 +117,11 @@ class JobsStore {
 					break;
 				}
 				case 'update': {
+					// Closed and withdrawn vacancies leave the feed
+					if (e.record.status !== JobsStatusOptions.processed) {
+						this.jobs = this.jobs.filter((j) => j.id !== e.record.id);
+						break;
+					}
 					const exists = this.jobs.find((j) => j.id === e.record.id);
 					if (exists) {
 						this.jobs = this.jobs.map((j) => (j.id === e.record.id ? e.record : j));
@@ -107,7 +136,7 @@ class JobsStore {
 			}
 		}, 
 		{
-			filter: `status = "processed" && userId = "${this.userId}"`
+			filter: `(status = "processed" || status = "closed" || status = "withdrawn") && userId = "${this.userId}"`
 		});
 	}
 
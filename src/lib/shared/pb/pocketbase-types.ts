@@ -11,7 +11,10 @@ export enum Collections {
 	Mfas = "_mfas",
 	Otps = "_otps",
 	Superusers = "_superusers",
+	Companies = "companies",
+	JobSightings = "job_sightings",
 	Jobs = "jobs",
+	Skills = "skills",
 	UserJobMap = "userJobMap",
 	Users = "users",
 }
@@ -94,33 +97,133 @@ export type SuperusersRecord = {
 	verified?: boolean
 }
 
+export type CompaniesRecord<Taliases = unknown, Tcontacts = unknown, Twebsites = unknown> = {
+	aliases?: null | Taliases
+	contacts?: null | Tcontacts
+	created: IsoAutoDateString
+	id: string
+	key: string
+	name: string
+	updated: IsoAutoDateString
+	websites?: null | Twebsites
+}
+
+export type JobSightingsRecord = {
+	channelId: string
+	created: IsoAutoDateString
+	deletedAt?: IsoDateString
+	distance?: number
+	id: string
+	job: RecordIdString
+	messageId?: number
+	seenAt?: IsoDateString
+	url?: string
+}
+
+export enum JobsSalaryPeriodOptions {
+	"hour" = "hour",
+	"month" = "month",
+	"year" = "year",
+}
+
+export enum JobsSalaryTaxOptions {
+	"gross" = "gross",
+	"net" = "net",
+}
+
 export enum JobsStatusOptions {
 	"raw" = "raw",
 	"processed" = "processed",
 	"processing" = "processing",
 	"rejected" = "rejected",
+	"failed" = "failed",
+	"closed" = "closed",
+	"withdrawn" = "withdrawn",
+}
+
+export enum JobsWithdrawnFromOptions {
+	"raw" = "raw",
+	"processed" = "processed",
+	"failed" = "failed",
 }
-export type JobsRecord<Traw = unknown, Tskills = unknown> = {
+export type JobsRecord<TalbumMessageIds = unknown, TattachmentIds = unknown, Tcontacts = unknown, TextractionWarnings = unknown, TmessageContacts = unknown, Traw = unknown, TrawSkills = unknown, Tskills = unknown> = {
+	albumId?: string
+	albumMessageIds?: null | TalbumMessageIds
+	attachmentIds?: null | TattachmentIds
+	attachments?: string[]
+	attempts?: number
+	canonicalSkills?: RecordIdString[]
 	channelId?: string
+	closedAt?: IsoDateString
 	company?: string
+	completionTokens?: number
+	contacts?: null | Tcontacts
 	created: IsoAutoDateString
 	currency?: string
 	description?: string
+	documentText?: string
+	editedAt?: IsoDateString
+	employer?: RecordIdString
+	expandedText?: string
+	extractedAt?: IsoDateString
+	extractionModel?: string
+	extractionWarnings?: null | TextractionWarnings
+	extractor?: string
+	firstSeen?: IsoDateString
 	grade?: string
 	hash?: string
 	id: string
 	isRemote?: boolean
+	lastError?: string
+	lastSeen?: IsoDateString
 	location?: string
+	messageContacts?: null | TmessageContacts
 	messageId?: number
+	originalHtml?: string
 	originalText: string
+	promptHash?: string
+	promptTokens?: number
+	promptVersion?: string
 	raw?: null | Traw
+	rawSkills?: null | TrawSkills
+	repostDistance?: number
+	repostOf?: RecordIdString
+	salaryBaseCurrency?: string
 	salaryMax?: number
+	salaryMaxMonthly?: number
 	salaryMin?: number
+	salaryMinMonthly?: number
+	salaryPeriod?: JobsSalaryPeriodOptions
+	salaryTax?: JobsSalaryTaxOptions
+	seenCount?: number
+	simhash?: string
 	skills?: null | Tskills
 	status?: JobsStatusOptions
+	statusReason?: string
+	subIndex?: number
 	title: string
+	unlinkable?: boolean
 	updated?: IsoAutoDateString
 	url?: string
+	withdrawnAt?: IsoDateString
+	withdrawnFrom?: JobsWithdrawnFromOptions
+}
+
+export enum SkillsCategoryOptions {
+	"language" = "language",
+	"framework" = "framework",
+	"cloud" = "cloud",
+	"db" = "db",
+	"tool" = "tool",
+	"other" = "other",
+}
+export type SkillsRecord<Taliases = unknown> = {
+	aliases?: null | Taliases
+	category?: SkillsCategoryOptions
+	created: IsoAutoDateString
+	id: string
+	name: string
+	updated: IsoAutoDateString
 }
 
 export type UserJobMapRecord = {
@@ -154,7 +257,10 @@ export type ExternalauthsResponse<Texpand = unknown> = Required<ExternalauthsRec
 export type MfasResponse<Texpand = unknown> = Required<MfasRecord> & BaseSystemFields<Texpand>
 export type OtpsResponse<Texpand = unknown> = Required<OtpsRecord> & BaseSystemFields<Texpand>
 export type SuperusersResponse<Texpand = unknown> = Required<SuperusersRecord> & AuthSystemFields<Texpand>
-export type JobsResponse<Traw = unknown, Tskills = unknown, Texpand = unknown> = Required<JobsRecord<Traw, Tskills>> & BaseSystemFields<Texpand>
+export type CompaniesResponse<Taliases = unknown, Tcontacts = unknown, Twebsites = unknown, Texpand = unknown> = Required<CompaniesRecord<Taliases, Tcontacts, Twebsites>> & BaseSystemFields<Texpand>
+export type JobSightingsResponse<Texpand = unknown> = Required<JobSightingsRecord> & BaseSystemFields<Texpand>
+export type JobsResponse<TalbumMessageIds = unknown, TattachmentIds = unknown, Tcontacts = unknown, TextractionWarnings = unknown, TmessageContacts = unknown, Traw = unknown, TrawSkills = unknown, Tskills = unknown, Texpand = unknown> = Required<JobsRecord<TalbumMessageIds, TattachmentIds, Tcontacts, TextractionWarnings, TmessageContacts, Traw, TrawSkills, Tskills>> & BaseSystemFields<Texpand>
+export type SkillsResponse<Taliases = unknown, Texpand = unknown> = Required<SkillsRecord<Taliases>> & BaseSystemFields<Texpand>
 export type UserJobMapResponse<Texpand = unknown> = Required<UserJobMapRecord> & BaseSystemFields<Texpand>
 export type UsersResponse<Tcv = unknown, Texpand = unknown> = Required<UsersRecord<Tcv>> & AuthSystemFields<Texpand>
 
@@ -166,7 +272,10 @@ export type CollectionRecords = {
 	_mfas: MfasRecord
 	_otps: OtpsRecord
 	_superusers: SuperusersRecord
+	companies: CompaniesRecord
+	job_sightings: JobSightingsRecord
 	jobs: JobsRecord
+	skills: SkillsRecord
 	userJobMap: UserJobMapRecord
 	users: UsersRecord
 }
@@ -177,7 +286,10 @@ export type CollectionResponses = {
 	_mfas: MfasResponse
 	_otps: OtpsResponse
 	_superusers: SuperusersResponse
+	companies: CompaniesResponse
+	job_sightings: JobSightingsResponse
 	jobs: JobsResponse
+	skills: SkillsResponse
 	userJobMap: UserJobMapResponse
 	users: UsersResponse
 }